@@ -0,0 +1,176 @@
+//
+// Created by zerjioang
+// https://github/zerjioang
+// Copyright (c) 2020. All rights reserved.
+//
+// SPDX-License-Identifier: GPL-3.0
+//
+
+package time32
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Clock is a source of wall clock readings that can replace the system
+// clock used by Epoch, Now and the Reuse* functions.
+type Clock interface {
+	Now() time.Time
+}
+
+// ManualClock is a Clock whose time only changes when it is explicitly set
+// or advanced. It is meant to be used in tests.
+type ManualClock struct {
+	mu  sync.RWMutex
+	now time.Time
+}
+
+// NewManualClock returns a ManualClock set to t.
+func NewManualClock(t time.Time) *ManualClock {
+	return &ManualClock{now: t}
+}
+
+// Now returns the current time of the clock.
+func (c *ManualClock) Now() time.Time {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.now
+}
+
+// Set moves the clock to t.
+func (c *ManualClock) Set(t time.Time) {
+	c.mu.Lock()
+	c.now = t
+	c.mu.Unlock()
+}
+
+// Advance moves the clock forward by d. A negative d moves it backward.
+func (c *ManualClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	c.now = c.now.Add(d)
+	c.mu.Unlock()
+}
+
+// reuseClock is the Clock returned by AsStdClock.
+type reuseClock struct{}
+
+// Now returns the cached time, as ReuseTime does.
+func (reuseClock) Now() time.Time {
+	return ReuseTime()
+}
+
+// AsStdClock returns a Clock whose Now method is served from the reuse
+// cache. Libraries that accept a clock with a Now() time.Time method, such
+// as the Clock interfaces of jonboulle/clockwork or benbjohnson/clock, can
+// be given an adapter wrapping it to transparently read the cached time:
+//
+//	type fastClock struct{ time32.Clock }
+//	// implement the remaining library methods on fastClock...
+//	lib.New(lib.WithClock(fastClock{time32.AsStdClock()}))
+func AsStdClock() Clock {
+	return reuseClock{}
+}
+
+// clockHolder wraps the installed Clock so that installedClock always
+// stores values of the same concrete type, even for a nil Clock.
+type clockHolder struct {
+	clock Clock
+}
+
+// installedClock stores the clockHolder of the Clock set with SetClock
+var installedClock atomic.Value
+
+// SetClock replaces the system clock with c. Passing nil restores the
+// system clock.
+//
+// While a Clock is installed, Epoch, Now and every Reuse* function read
+// the Clock directly instead of the system clock or the cache, so any
+// change made to a ManualClock is reflected immediately, without waiting
+// for the cache ticker.
+func SetClock(c Clock) {
+	updateSlowPath(func() {
+		installedClock.Store(clockHolder{clock: c})
+	})
+}
+
+// loadClock returns the installed Clock, or nil if the system clock is in use.
+func loadClock() Clock {
+	h, _ := installedClock.Load().(clockHolder)
+	return h.clock
+}
+
+// FixedEpoch installs a clock frozen at the Unix second sec, so that Epoch
+// returns exactly Time32(sec) without reading the system clock, and returns
+// a function restoring the previously installed Clock. It is meant for
+// deterministic tests:
+//
+//	defer time32.FixedEpoch(1588228661)()
+//
+// An offset set with SetClockOffset still applies on top of it.
+func FixedEpoch(sec uint32) func() {
+	prev := loadClock()
+	SetClock(NewManualClock(time.Unix(int64(sec), 0)))
+	return func() {
+		SetClock(prev)
+	}
+}
+
+// clockOffset is added, in nanoseconds, to every reading made by Epoch, Now
+// and the Reuse* functions. It is accessed atomically because the cache
+// ticker goroutine reads it on every tick.
+var clockOffset int64
+
+// SetClockOffset makes Epoch, Now and every Reuse* function report the time
+// shifted by d, simulating a clock that runs ahead (d > 0) or behind (d < 0).
+// It is meant for tests exercising clock skew handling. It is safe to call
+// while other goroutines read the time, though readings taken around the
+// change may use either offset.
+func SetClockOffset(d Duration) {
+	updateSlowPath(func() {
+		atomic.StoreInt64(&clockOffset, int64(d))
+	})
+}
+
+// ClockOffset returns the offset set with SetClockOffset.
+func ClockOffset() Duration {
+	return Duration(atomic.LoadInt64(&clockOffset))
+}
+
+// Backend selects how the package reads the wall clock.
+type Backend int32
+
+const (
+	// BackendLinkname reads the wall clock through the runtime's time.now,
+	// reached with go:linkname. It is the default.
+	BackendLinkname Backend = iota
+	// BackendSyscall reads the wall clock by decomposing time.Now, which
+	// only relies on the exported standard library API.
+	BackendSyscall
+)
+
+// clockBackend is the Backend used by wallNow
+var clockBackend int32
+
+// SetClockBackend selects how Epoch, Now and the other functions reading
+// the system clock get the wall time. Monotonic readings always come from
+// the runtime clock, so Times taken under either backend stay comparable;
+// build with the time32_nolinkname tag to avoid go:linkname altogether.
+// It panics if b is not a known Backend.
+func SetClockBackend(b Backend) {
+	if b != BackendLinkname && b != BackendSyscall {
+		panic("time32: unknown clock backend")
+	}
+	atomic.StoreInt32(&clockBackend, int32(b))
+}
+
+// wallNow reads the system wall clock and the runtime monotonic clock
+// using the Backend selected with SetClockBackend.
+func wallNow() (sec int64, nsec int32, mono int64) {
+	if atomic.LoadInt32(&clockBackend) == int32(BackendSyscall) {
+		t := time.Now()
+		return t.Unix(), int32(t.Nanosecond()), runtimeNano()
+	}
+	return time_now()
+}
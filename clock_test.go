@@ -0,0 +1,153 @@
+//
+// Created by zerjioang
+// https://github/zerjioang
+// Copyright (c) 2020. All rights reserved.
+//
+// SPDX-License-Identifier: GPL-3.0
+//
+
+package time32
+
+import (
+	"github.com/stretchr/testify/assert"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestClock(t *testing.T) {
+	t.Run("manual-clock-reuse", func(t *testing.T) {
+		clock := NewManualClock(time.Unix(1588228661, 0))
+		SetClock(clock)
+		defer SetClock(nil)
+
+		assert.Equal(t, int64(1588228661), ReuseUnix())
+		clock.Advance(time.Hour)
+		assert.Equal(t, int64(1588228661+3600), ReuseUnix())
+		assert.Equal(t, int64(1588228661+3600)*1e9, ReuseUnixNano())
+		assert.Equal(t, int64(1588228661+3600), ReuseTime().Unix())
+	})
+	t.Run("manual-clock-epoch-and-now", func(t *testing.T) {
+		clock := NewManualClock(time.Unix(1588228661, 500))
+		SetClock(clock)
+		defer SetClock(nil)
+
+		assert.Equal(t, Time32(1588228661), Epoch())
+		assert.Equal(t, int64(1588228661), Now().Unix())
+		assert.Equal(t, 500, Now().Nanosecond())
+	})
+	t.Run("system-clock-restored", func(t *testing.T) {
+		SetClock(NewManualClock(time.Unix(0, 0)))
+		SetClock(nil)
+		assert.InDelta(t, time.Now().Unix(), int64(Epoch()), 1)
+	})
+	t.Run("clock-offset", func(t *testing.T) {
+		before := Epoch()
+		SetClockOffset(Hour)
+		defer SetClockOffset(0)
+
+		assert.Equal(t, Hour, ClockOffset())
+		assert.InDelta(t, int64(before)+3600, int64(Epoch()), 1)
+		assert.InDelta(t, int64(before)+3600, Now().Unix(), 1)
+		assert.InDelta(t, int64(before)+3600, ReuseUnix(), 1)
+		// let the cache ticker read the offset concurrently (checked by -race)
+		time.Sleep(2 * defaultPrecision)
+		assert.InDelta(t, int64(before)+3600, ReuseUnix(), 1)
+	})
+	t.Run("clock-offset-with-manual-clock", func(t *testing.T) {
+		SetClock(NewManualClock(time.Unix(1588228661, 0)))
+		SetClockOffset(-Hour)
+		defer SetClock(nil)
+		defer SetClockOffset(0)
+
+		assert.Equal(t, Time32(1588228661-3600), Epoch())
+		assert.Equal(t, int64(1588228661-3600), ReuseUnix())
+	})
+	t.Run("slow-path-flag", func(t *testing.T) {
+		assert.Equal(t, int32(0), atomic.LoadInt32(&slowPath))
+		SetClock(NewManualClock(time.Unix(1588228661, 0)))
+		SetClockOffset(Hour)
+		SetClock(nil)
+		assert.Equal(t, int32(1), atomic.LoadInt32(&slowPath))
+		SetClockOffset(0)
+		assert.Equal(t, int32(0), atomic.LoadInt32(&slowPath))
+		SetMaxStaleness(Second)
+		assert.Equal(t, int32(1), atomic.LoadInt32(&slowPath))
+		SetMaxStaleness(0)
+		assert.Equal(t, int32(0), atomic.LoadInt32(&slowPath))
+	})
+	t.Run("as-std-clock", func(t *testing.T) {
+		var clock interface{ Now() time.Time } = AsStdClock()
+		assert.InDelta(t, ReuseTime().UnixNano(), clock.Now().UnixNano(), float64(200*time.Millisecond))
+
+		SetClock(NewManualClock(time.Unix(1588228661, 0)))
+		defer SetClock(nil)
+		assert.Equal(t, ReuseTime(), clock.Now())
+	})
+}
+
+func TestFixedEpoch(t *testing.T) {
+	t.Run("deterministic", func(t *testing.T) {
+		restore := FixedEpoch(1588228661)
+		assert.Equal(t, Time32(1588228661), Epoch())
+		assert.Equal(t, "2020-04-30T06:37:41Z", Epoch().String())
+		assert.Equal(t, []byte{0x5e, 0xaa, 0x72, 0x35}, AppendEpoch(nil))
+		assert.Equal(t, 19*Second, Time32(1588228680).Remaining())
+		restore()
+		assert.Nil(t, loadClock())
+		assert.InDelta(t, time.Now().Unix(), int64(Epoch()), 1)
+	})
+	t.Run("restores-previous", func(t *testing.T) {
+		clock := NewManualClock(time.Unix(42, 0))
+		SetClock(clock)
+		defer SetClock(nil)
+		func() {
+			defer FixedEpoch(1588228661)()
+			assert.Equal(t, Time32(1588228661), Epoch())
+		}()
+		assert.Equal(t, Time32(42), Epoch())
+	})
+}
+
+func TestClockBackend(t *testing.T) {
+	defer SetClockBackend(BackendLinkname)
+	for _, b := range []Backend{BackendLinkname, BackendSyscall} {
+		SetClockBackend(b)
+		for i := 0; i < 1000; i++ {
+			before := time.Now().Unix()
+			epoch := int64(Epoch())
+			after := time.Now().Unix()
+			assert.True(t, before <= epoch && epoch <= after)
+		}
+		assert.InDelta(t, time.Now().UnixNano(), Now().UnixNano(), float64(time.Second))
+		tt := Now()
+		assert.True(t, Since(tt) >= 0)
+	}
+	assert.Panics(t, func() { SetClockBackend(Backend(9)) })
+}
+
+func BenchmarkClockBackend(b *testing.B) {
+	defer SetClockBackend(BackendLinkname)
+	b.Run("linkname", func(b *testing.B) {
+		SetClockBackend(BackendLinkname)
+		b.ReportAllocs()
+		var epoch Time32
+		for i := 0; i < b.N; i++ {
+			epoch = Epoch()
+		}
+		if epoch == 0 {
+			b.Log("time is zero")
+		}
+	})
+	b.Run("syscall", func(b *testing.B) {
+		SetClockBackend(BackendSyscall)
+		b.ReportAllocs()
+		var epoch Time32
+		for i := 0; i < b.N; i++ {
+			epoch = Epoch()
+		}
+		if epoch == 0 {
+			b.Log("time is zero")
+		}
+	})
+}
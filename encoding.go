@@ -0,0 +1,388 @@
+//
+// Created by zerjioang
+// https://github/zerjioang
+// Copyright (c) 2020. All rights reserved.
+//
+// SPDX-License-Identifier: GPL-3.0
+//
+
+package time32
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/gob"
+	"errors"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// pgEpochOffset is the number of seconds between the Unix epoch and the
+// PostgreSQL epoch (2000-01-01 00:00:00 UTC).
+const pgEpochOffset int64 = 946684800
+
+// ErrOutOfRange is returned when a value can not be represented as a Time32,
+// that is, when it falls before 1970-01-01 or after 2106-02-07T06:28:15Z.
+var ErrOutOfRange = errors.New("time32: value out of uint32 range")
+
+// ErrShortBuffer is returned when a buffer is too short to hold a Time32.
+var ErrShortBuffer = errors.New("time32: buffer too short")
+
+// ErrChecksum is returned when a checked record fails verification.
+var ErrChecksum = errors.New("time32: checksum mismatch")
+
+// Scan implements the sql.Scanner interface.
+//
+// Supported sources are integer epoch values, time.Time values, decimal
+// epoch strings and the PostgreSQL binary timestamp format. A []byte source
+// is decoded as a PostgreSQL binary timestamp (a big endian int64 holding
+// microseconds since 2000-01-01) when it is exactly 8 bytes long and not
+// made only of ASCII digits. Binary timestamps for any date in the Time32
+// range contain at least one non-digit byte (their first byte is 0x00 after
+// 2000 and 0xFF before), so they never collide with a decimal text value.
+// Any other []byte is parsed as a decimal epoch.
+func (t *Time32) Scan(src interface{}) error {
+	var v Time32
+	var err error
+	switch s := src.(type) {
+	case nil:
+	case int64:
+		v, err = fromUnix(s)
+	case time.Time:
+		v, err = fromUnix(s.Unix())
+	case string:
+		v, err = parseUnix(s)
+	case []byte:
+		if isPgBinaryTimestamp(s) {
+			v, err = fromPgBinary(s)
+		} else {
+			v, err = parseUnix(string(s))
+		}
+	default:
+		return errors.New("time32: unsupported Scan source type")
+	}
+	if err != nil {
+		return err
+	}
+	*t = v
+	return nil
+}
+
+// isPgBinaryTimestamp reports whether b looks like a PostgreSQL binary timestamp.
+func isPgBinaryTimestamp(b []byte) bool {
+	if len(b) != 8 {
+		return false
+	}
+	for _, c := range b {
+		if c < '0' || c > '9' {
+			return true
+		}
+	}
+	return false
+}
+
+// fromPgBinary converts a PostgreSQL binary timestamp into a Time32,
+// truncating the sub-second component.
+func fromPgBinary(b []byte) (Time32, error) {
+	usec := int64(binary.BigEndian.Uint64(b))
+	sec := usec / 1e6
+	if usec%1e6 < 0 {
+		sec--
+	}
+	return fromUnix(sec + pgEpochOffset)
+}
+
+// parseUnix parses a decimal epoch value.
+func parseUnix(s string) (Time32, error) {
+	v, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	return fromUnix(v)
+}
+
+// epochReader is the io.Reader returned by EpochReader.
+type epochReader struct {
+	counter uint32
+	block   [8]byte
+	off     int
+}
+
+// EpochReader returns a reader that produces a stream of time derived bytes.
+// The stream is made of 8 byte blocks, each holding the little endian bytes
+// of the current Epoch followed by the little endian bytes of a counter that
+// increases on every block, so two reads never return the same bytes.
+//
+// The output is NOT cryptographically secure: it is fully predictable from
+// the current time. Use crypto/rand for keys, tokens or secure nonces.
+// The returned reader is not safe for concurrent use.
+func EpochReader() io.Reader {
+	return &epochReader{off: 8}
+}
+
+// Read implements the io.Reader interface. It always fills p entirely.
+func (r *epochReader) Read(p []byte) (int, error) {
+	n := 0
+	for n < len(p) {
+		if r.off == len(r.block) {
+			r.counter++
+			binary.LittleEndian.PutUint32(r.block[:4], uint32(Epoch()))
+			binary.LittleEndian.PutUint32(r.block[4:], r.counter)
+			r.off = 0
+		}
+		c := copy(p[n:], r.block[r.off:])
+		r.off += c
+		n += c
+	}
+	return n, nil
+}
+
+// AppendEpoch appends the cached epoch value to b as 4 big endian bytes and
+// returns the extended buffer. It does not allocate when b has enough
+// capacity, which makes it suitable for prefixing append-only log records.
+func AppendEpoch(b []byte) []byte {
+	e := uint32(loadSnapshot().epoch)
+	return append(b, byte(e>>24), byte(e>>16), byte(e>>8), byte(e))
+}
+
+// ReadEpoch reads a Time32 written by AppendEpoch from the start of b and
+// returns it together with the number of bytes consumed.
+func ReadEpoch(b []byte) (Time32, int, error) {
+	if len(b) < 4 {
+		return 0, 0, ErrShortBuffer
+	}
+	return Time32(binary.BigEndian.Uint32(b)), 4, nil
+}
+
+// PutLE writes t into the first 4 bytes of b in little endian order, the
+// layout used by fixed-width fields in Cap'n Proto and FlatBuffers.
+// It panics if len(b) < 4.
+func (t Time32) PutLE(b []byte) {
+	if len(b) < 4 {
+		panic("time32: buffer too short for PutLE")
+	}
+	binary.LittleEndian.PutUint32(b, uint32(t))
+}
+
+// PutBE writes t into the first 4 bytes of b in big endian order.
+// It panics if len(b) < 4.
+func (t Time32) PutBE(b []byte) {
+	if len(b) < 4 {
+		panic("time32: buffer too short for PutBE")
+	}
+	binary.BigEndian.PutUint32(b, uint32(t))
+}
+
+// GetLE reads a Time32 written by PutLE from the first 4 bytes of b.
+// It panics if len(b) < 4.
+func GetLE(b []byte) Time32 {
+	if len(b) < 4 {
+		panic("time32: buffer too short for GetLE")
+	}
+	return Time32(binary.LittleEndian.Uint32(b))
+}
+
+// GetBE reads a Time32 written by PutBE from the first 4 bytes of b.
+// It panics if len(b) < 4.
+func GetBE(b []byte) Time32 {
+	if len(b) < 4 {
+		panic("time32: buffer too short for GetBE")
+	}
+	return Time32(binary.BigEndian.Uint32(b))
+}
+
+// ReadAt reads the Time32 stored in the 4 bytes of b starting at offset,
+// in big or little endian order. It returns ErrShortBuffer if those bytes
+// are not within b.
+func ReadAt(b []byte, offset int, bigEndian bool) (Time32, error) {
+	if offset < 0 || offset > len(b)-4 {
+		return 0, ErrShortBuffer
+	}
+	if bigEndian {
+		return GetBE(b[offset:]), nil
+	}
+	return GetLE(b[offset:]), nil
+}
+
+// WriteAt stores t in the 4 bytes of b starting at offset, in big or
+// little endian order, leaving the rest of b untouched. It returns
+// ErrShortBuffer, without writing anything, if those bytes are not within b.
+func WriteAt(b []byte, offset int, t Time32, bigEndian bool) error {
+	if offset < 0 || offset > len(b)-4 {
+		return ErrShortBuffer
+	}
+	if bigEndian {
+		t.PutBE(b[offset:])
+	} else {
+		t.PutLE(b[offset:])
+	}
+	return nil
+}
+
+// RegisterGob registers Time32 with encoding/gob, which is needed to gob
+// encode a Time32 stored in an interface{} value. Concrete Time32 fields
+// need no registration. It is opt-in because gob registration is global
+// and permanent, and registering at init would claim the type name for
+// every program importing this package. It is safe to call more than once.
+func RegisterGob() {
+	gob.Register(Time32(0))
+}
+
+// LineError reports the line on which ParseLines failed.
+type LineError struct {
+	// Line is the 1-based number of the offending line.
+	Line int
+	// Err is the parse or range error found on that line.
+	Err error
+}
+
+// Error implements the error interface.
+func (e *LineError) Error() string {
+	return "time32: line " + strconv.Itoa(e.Line) + ": " + e.Err.Error()
+}
+
+// Unwrap returns the underlying error, so errors.Is(err, ErrOutOfRange)
+// works on the result of ParseLines.
+func (e *LineError) Unwrap() error {
+	return e.Err
+}
+
+// ParseLines reads r line by line, parsing each line as an integer epoch
+// value. Surrounding whitespace is ignored and blank lines are skipped.
+// On the first invalid or out of range line it returns a *LineError.
+func ParseLines(r io.Reader) ([]Time32, error) {
+	var ts []Time32
+	if l, ok := r.(interface{ Len() int }); ok {
+		// most epoch lines are 10 digits plus the newline
+		ts = make([]Time32, 0, l.Len()/11)
+	}
+	sc := bufio.NewScanner(r)
+	for line := 1; sc.Scan(); line++ {
+		s := strings.TrimSpace(sc.Text())
+		if s == "" {
+			continue
+		}
+		t, err := parseUnix(s)
+		if err != nil {
+			return nil, &LineError{Line: line, Err: err}
+		}
+		ts = append(ts, t)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	return ts, nil
+}
+
+// Getenv reads the environment variable named by key and parses it either
+// as an integer epoch value or as an RFC3339 timestamp. The found result is
+// false, with a nil error, when the variable is not set.
+func Getenv(key string) (Time32, bool, error) {
+	s, found := os.LookupEnv(key)
+	if !found {
+		return 0, false, nil
+	}
+	t, err := parseEpochOrRFC3339(s)
+	return t, true, err
+}
+
+// MarshalChecked returns a self verifying 5 byte record made of the 4 big
+// endian bytes of t followed by their CRC-8 checksum (polynomial 0x07).
+func (t Time32) MarshalChecked() []byte {
+	b := make([]byte, 5)
+	binary.BigEndian.PutUint32(b, uint32(t))
+	b[4] = crc8(b[:4])
+	return b
+}
+
+// UnmarshalChecked decodes a record written by MarshalChecked, returning
+// ErrChecksum if the record is corrupted.
+func UnmarshalChecked(b []byte) (Time32, error) {
+	if len(b) < 5 {
+		return 0, ErrShortBuffer
+	}
+	if crc8(b[:4]) != b[4] {
+		return 0, ErrChecksum
+	}
+	return Time32(binary.BigEndian.Uint32(b)), nil
+}
+
+// crc8 computes the CRC-8 checksum of b using the polynomial x^8+x^2+x+1.
+func crc8(b []byte) byte {
+	var crc byte
+	for _, c := range b {
+		crc ^= c
+		for i := 0; i < 8; i++ {
+			if crc&0x80 != 0 {
+				crc = crc<<1 ^ 0x07
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return crc
+}
+
+// JSONFormat selects how Time32 values are written by MarshalJSON.
+type JSONFormat int32
+
+const (
+	// JSONSeconds writes the epoch seconds as a JSON number. It is the
+	// default and matches the encoding of a plain uint32.
+	JSONSeconds JSONFormat = iota
+	// JSONMillis writes the epoch milliseconds as a JSON number, as
+	// expected by JavaScript's Date.
+	JSONMillis
+)
+
+// jsonFormat is the JSONFormat used by MarshalJSON and UnmarshalJSON
+var jsonFormat int32
+
+// SetJSONFormat sets the format used by every Time32 MarshalJSON call and
+// the unit UnmarshalJSON assumes for numbers. It is process wide, so it is
+// meant to be set once at startup. It panics if f is not a known format.
+func SetJSONFormat(f JSONFormat) {
+	if f < JSONSeconds || f > JSONMillis {
+		panic("time32: unknown JSON format")
+	}
+	atomic.StoreInt32(&jsonFormat, int32(f))
+}
+
+// MarshalJSON implements json.Marshaler using the format set with
+// SetJSONFormat.
+func (t Time32) MarshalJSON() ([]byte, error) {
+	if JSONFormat(atomic.LoadInt32(&jsonFormat)) == JSONMillis {
+		return strconv.AppendInt(nil, int64(t)*1000, 10), nil
+	}
+	return strconv.AppendUint(nil, uint64(t), 10), nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler. It accepts the JSON numbers
+// a plain uint32 accepts, read as seconds, except under JSONMillis where
+// they are read as milliseconds and truncated to the second. Strings are
+// rejected in every format. As with the standard library, null is a no-op.
+func (t *Time32) UnmarshalJSON(b []byte) error {
+	if string(b) == "null" {
+		return nil
+	}
+	n, err := strconv.ParseInt(string(b), 10, 64)
+	if err != nil {
+		return err
+	}
+	if JSONFormat(atomic.LoadInt32(&jsonFormat)) == JSONMillis {
+		if n < 0 {
+			return ErrOutOfRange
+		}
+		n /= 1000
+	}
+	v, err := fromUnix(n)
+	if err != nil {
+		return err
+	}
+	*t = v
+	return nil
+}
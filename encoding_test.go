@@ -0,0 +1,306 @@
+//
+// Created by zerjioang
+// https://github/zerjioang
+// Copyright (c) 2020. All rights reserved.
+//
+// SPDX-License-Identifier: GPL-3.0
+//
+
+package time32
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestScan(t *testing.T) {
+	t.Run("pg-binary-timestamp", func(t *testing.T) {
+		// 2020-04-30T06:37:41.5Z as microseconds since 2000-01-01
+		b := make([]byte, 8)
+		binary.BigEndian.PutUint64(b, uint64((1588228661-946684800)*1e6+500000))
+		var tt Time32
+		assert.NoError(t, tt.Scan(b))
+		assert.Equal(t, Time32(1588228661), tt)
+	})
+	t.Run("pg-binary-timestamp-before-2000", func(t *testing.T) {
+		// 1999-12-31T23:59:59.5Z
+		b := make([]byte, 8)
+		usec := int64(-500000)
+		binary.BigEndian.PutUint64(b, uint64(usec))
+		var tt Time32
+		assert.NoError(t, tt.Scan(b))
+		assert.Equal(t, Time32(946684799), tt)
+	})
+	t.Run("decimal-text", func(t *testing.T) {
+		var tt Time32
+		assert.NoError(t, tt.Scan([]byte("1588228661")))
+		assert.Equal(t, Time32(1588228661), tt)
+		assert.NoError(t, tt.Scan([]byte("12345678")))
+		assert.Equal(t, Time32(12345678), tt)
+	})
+	t.Run("integer-and-time", func(t *testing.T) {
+		var tt Time32
+		assert.NoError(t, tt.Scan(int64(1588228661)))
+		assert.Equal(t, Time32(1588228661), tt)
+		assert.NoError(t, tt.Scan(time.Unix(1588228661, 0)))
+		assert.Equal(t, Time32(1588228661), tt)
+	})
+	t.Run("out-of-range", func(t *testing.T) {
+		var tt Time32
+		assert.Equal(t, ErrOutOfRange, tt.Scan(int64(-1)))
+		assert.Equal(t, ErrOutOfRange, tt.Scan(int64(1<<32)))
+	})
+}
+
+func TestEpochReader(t *testing.T) {
+	t.Run("reads-differ", func(t *testing.T) {
+		r := EpochReader()
+		a := make([]byte, 16)
+		b := make([]byte, 16)
+		n, err := r.Read(a)
+		assert.NoError(t, err)
+		assert.Equal(t, 16, n)
+		n, err = r.Read(b)
+		assert.NoError(t, err)
+		assert.Equal(t, 16, n)
+		assert.NotEqual(t, a, b)
+	})
+	t.Run("partial-blocks", func(t *testing.T) {
+		SetClock(NewManualClock(time.Unix(1588228661, 0)))
+		defer SetClock(nil)
+		r := EpochReader()
+		a := make([]byte, 3)
+		b := make([]byte, 7)
+		_, _ = r.Read(a)
+		_, _ = r.Read(b)
+		full := append(a, b...)
+		assert.Equal(t, uint32(1588228661), binary.LittleEndian.Uint32(full[:4]))
+		assert.Equal(t, []byte{1, 0, 0, 0}, full[4:8])
+		assert.Equal(t, full[:2], full[8:10])
+	})
+}
+
+func TestAppendEpoch(t *testing.T) {
+	t.Run("round-trip", func(t *testing.T) {
+		SetClock(NewManualClock(time.Unix(1588228661, 0)))
+		defer SetClock(nil)
+
+		b := AppendEpoch([]byte("record"))
+		assert.Len(t, b, 10)
+		v, n, err := ReadEpoch(b[6:])
+		assert.NoError(t, err)
+		assert.Equal(t, 4, n)
+		assert.Equal(t, Time32(1588228661), v)
+	})
+	t.Run("short-buffer", func(t *testing.T) {
+		_, n, err := ReadEpoch([]byte{1, 2, 3})
+		assert.Equal(t, ErrShortBuffer, err)
+		assert.Equal(t, 0, n)
+	})
+}
+
+func BenchmarkAppendEpoch(b *testing.B) {
+	b.Run("append-epoch", func(b *testing.B) {
+		buf := make([]byte, 0, 64)
+		b.ReportAllocs()
+		b.SetBytes(4)
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			buf = AppendEpoch(buf[:0])
+		}
+	})
+}
+
+func TestFixedField(t *testing.T) {
+	t.Run("little-endian", func(t *testing.T) {
+		b := make([]byte, 6)
+		Time32(1588228661).PutLE(b[1:])
+		assert.Equal(t, []byte{0, 0x35, 0x72, 0xaa, 0x5e, 0}, b)
+		assert.Equal(t, Time32(1588228661), GetLE(b[1:]))
+	})
+	t.Run("big-endian", func(t *testing.T) {
+		b := make([]byte, 4)
+		Time32(1588228661).PutBE(b)
+		assert.Equal(t, []byte{0x5e, 0xaa, 0x72, 0x35}, b)
+		assert.Equal(t, Time32(1588228661), GetBE(b))
+	})
+	t.Run("short-buffer", func(t *testing.T) {
+		b := make([]byte, 3)
+		assert.Panics(t, func() { Time32(1).PutLE(b) })
+		assert.Panics(t, func() { Time32(1).PutBE(b) })
+		assert.Panics(t, func() { GetLE(b) })
+		assert.Panics(t, func() { GetBE(b) })
+		assert.Equal(t, []byte{0, 0, 0}, b)
+	})
+}
+
+func TestReadWriteAt(t *testing.T) {
+	t.Run("non-zero-offset", func(t *testing.T) {
+		b := []byte{0xff, 0xff, 0xff, 0, 0, 0, 0, 0xff}
+		assert.NoError(t, WriteAt(b, 3, 1588228661, true))
+		assert.Equal(t, []byte{0xff, 0xff, 0xff, 0x5e, 0xaa, 0x72, 0x35, 0xff}, b)
+		v, err := ReadAt(b, 3, true)
+		assert.NoError(t, err)
+		assert.Equal(t, Time32(1588228661), v)
+
+		assert.NoError(t, WriteAt(b, 4, 1588228661, false))
+		assert.Equal(t, []byte{0xff, 0xff, 0xff, 0x5e, 0x35, 0x72, 0xaa, 0x5e}, b)
+		v, err = ReadAt(b, 4, false)
+		assert.NoError(t, err)
+		assert.Equal(t, Time32(1588228661), v)
+	})
+	t.Run("bounds", func(t *testing.T) {
+		b := make([]byte, 8)
+		_, err := ReadAt(b, 5, true)
+		assert.Equal(t, ErrShortBuffer, err)
+		_, err = ReadAt(b, -1, false)
+		assert.Equal(t, ErrShortBuffer, err)
+		assert.Equal(t, ErrShortBuffer, WriteAt(b, 5, 1, true))
+		assert.Equal(t, ErrShortBuffer, WriteAt(b, -1, 1, false))
+		assert.Equal(t, ErrShortBuffer, WriteAt(nil, 0, 1, false))
+		assert.Equal(t, make([]byte, 8), b)
+	})
+}
+
+func TestParseLines(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		ts, err := ParseLines(strings.NewReader("0\n1588228661\r\n\n  4294967295  \n42"))
+		assert.NoError(t, err)
+		assert.Equal(t, []Time32{0, 1588228661, 4294967295, 42}, ts)
+	})
+	t.Run("empty", func(t *testing.T) {
+		ts, err := ParseLines(strings.NewReader(""))
+		assert.NoError(t, err)
+		assert.Empty(t, ts)
+	})
+	t.Run("out-of-range", func(t *testing.T) {
+		_, err := ParseLines(strings.NewReader("1\n2\n4294967296\n3\n"))
+		var lineErr *LineError
+		assert.True(t, errors.As(err, &lineErr))
+		assert.Equal(t, 3, lineErr.Line)
+		assert.True(t, errors.Is(err, ErrOutOfRange))
+		assert.Equal(t, "time32: line 3: time32: value out of uint32 range", err.Error())
+	})
+	t.Run("invalid", func(t *testing.T) {
+		_, err := ParseLines(strings.NewReader("1\nnope\n"))
+		var lineErr *LineError
+		assert.True(t, errors.As(err, &lineErr))
+		assert.Equal(t, 2, lineErr.Line)
+	})
+}
+
+func TestGetenv(t *testing.T) {
+	t.Run("unset", func(t *testing.T) {
+		v, found, err := Getenv("TIME32_TEST_UNSET")
+		assert.NoError(t, err)
+		assert.False(t, found)
+		assert.Equal(t, Time32(0), v)
+	})
+	t.Run("integer", func(t *testing.T) {
+		t.Setenv("TIME32_TEST_VALUE", "1588228661")
+		v, found, err := Getenv("TIME32_TEST_VALUE")
+		assert.NoError(t, err)
+		assert.True(t, found)
+		assert.Equal(t, Time32(1588228661), v)
+	})
+	t.Run("rfc3339", func(t *testing.T) {
+		t.Setenv("TIME32_TEST_VALUE", "2020-04-30T06:37:41Z")
+		v, found, err := Getenv("TIME32_TEST_VALUE")
+		assert.NoError(t, err)
+		assert.True(t, found)
+		assert.Equal(t, Time32(1588228661), v)
+	})
+	t.Run("malformed", func(t *testing.T) {
+		t.Setenv("TIME32_TEST_VALUE", "yesterday")
+		_, found, err := Getenv("TIME32_TEST_VALUE")
+		assert.Error(t, err)
+		assert.True(t, found)
+	})
+}
+
+func TestMarshalChecked(t *testing.T) {
+	t.Run("round-trip", func(t *testing.T) {
+		b := Time32(1588228661).MarshalChecked()
+		assert.Len(t, b, 5)
+		v, err := UnmarshalChecked(b)
+		assert.NoError(t, err)
+		assert.Equal(t, Time32(1588228661), v)
+	})
+	t.Run("corrupted", func(t *testing.T) {
+		for i := 0; i < 5; i++ {
+			b := Time32(1588228661).MarshalChecked()
+			b[i] ^= 0x10
+			_, err := UnmarshalChecked(b)
+			assert.Equal(t, ErrChecksum, err)
+		}
+	})
+	t.Run("short-buffer", func(t *testing.T) {
+		_, err := UnmarshalChecked([]byte{1, 2, 3, 4})
+		assert.Equal(t, ErrShortBuffer, err)
+	})
+	t.Run("crc8-check-value", func(t *testing.T) {
+		// standard CRC-8 check value for "123456789"
+		assert.Equal(t, byte(0xF4), crc8([]byte("123456789")))
+	})
+}
+
+func TestJSON(t *testing.T) {
+	type event struct {
+		At Time32 `json:"at"`
+	}
+	t.Run("seconds", func(t *testing.T) {
+		b, err := json.Marshal(event{At: 1588228661})
+		assert.NoError(t, err)
+		assert.Equal(t, `{"at":1588228661}`, string(b))
+		var e event
+		assert.NoError(t, json.Unmarshal(b, &e))
+		assert.Equal(t, Time32(1588228661), e.At)
+	})
+	t.Run("millis", func(t *testing.T) {
+		SetJSONFormat(JSONMillis)
+		defer SetJSONFormat(JSONSeconds)
+		b, err := json.Marshal(event{At: 1588228661})
+		assert.NoError(t, err)
+		assert.Equal(t, `{"at":1588228661000}`, string(b))
+		var e event
+		assert.NoError(t, json.Unmarshal([]byte(`{"at":1588228661999}`), &e))
+		assert.Equal(t, Time32(1588228661), e.At)
+		assert.Error(t, json.Unmarshal([]byte(`{"at":"1588228661000"}`), &e))
+		assert.Error(t, json.Unmarshal([]byte(`{"at":-1000}`), &e))
+	})
+	t.Run("invalid", func(t *testing.T) {
+		var e event
+		assert.Error(t, json.Unmarshal([]byte(`{"at":4294967296}`), &e))
+		assert.Error(t, json.Unmarshal([]byte(`{"at":"yesterday"}`), &e))
+		// strings are rejected, as they are for a plain uint32
+		assert.Error(t, json.Unmarshal([]byte(`{"at":"1588228661"}`), &e))
+		assert.Error(t, json.Unmarshal([]byte(`{"at":"2020-04-30T06:37:41Z"}`), &e))
+		assert.Error(t, json.Unmarshal([]byte(`{"at":1.5}`), &e))
+		e.At = 42
+		assert.NoError(t, json.Unmarshal([]byte(`{"at":null}`), &e))
+		assert.Equal(t, Time32(42), e.At)
+		assert.Panics(t, func() { SetJSONFormat(JSONFormat(2)) })
+	})
+}
+
+func TestRegisterGob(t *testing.T) {
+	t.Run("interface-field", func(t *testing.T) {
+		RegisterGob()
+		RegisterGob()
+		type record struct {
+			Value interface{}
+		}
+		var buf bytes.Buffer
+		assert.NoError(t, gob.NewEncoder(&buf).Encode(record{Value: Time32(1588228661)}))
+		var got record
+		assert.NoError(t, gob.NewDecoder(&buf).Decode(&got))
+		assert.Equal(t, Time32(1588228661), got.Value)
+	})
+}
@@ -15,7 +15,7 @@ import (
 )
 
 func main() {
-	fmt.Println("current time using time32.Epoch is: ", time32.Epoch())
+	fmt.Println("current time using time32.Epoch is: ", uint32(time32.Epoch()))
 	fmt.Println("current time using time32.ReuseUnix is: ", time32.ReuseUnix())
 	fmt.Println("current time using time.Now().Unix() is: ", time.Now().Unix())
 }
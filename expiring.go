@@ -0,0 +1,80 @@
+//
+// Created by zerjioang
+// https://github/zerjioang
+// Copyright (c) 2020. All rights reserved.
+//
+// SPDX-License-Identifier: GPL-3.0
+//
+
+package time32
+
+import (
+	"sync"
+)
+
+// ExpiringSet is a set of string keys that are forgotten ttl after they
+// were added, as used for stream deduplication windows. Insertion times
+// are Time32 values taken from the cached epoch, so the ttl has a one
+// second resolution. It is safe for concurrent use.
+type ExpiringSet struct {
+	mu   sync.Mutex
+	ttl  int64
+	seen map[string]Time32
+	// order holds the keys in insertion order, oldest first, so expired
+	// keys are evicted without scanning the whole map
+	order []expiringEntry
+}
+
+// expiringEntry records when a key was added to an ExpiringSet
+type expiringEntry struct {
+	key string
+	at  Time32
+}
+
+// NewExpiringSet returns an empty ExpiringSet keeping keys for ttl,
+// truncated to whole seconds. It panics if ttl is less than a second.
+func NewExpiringSet(ttl Duration) *ExpiringSet {
+	if ttl < Second {
+		panic("time32: ExpiringSet ttl below one second")
+	}
+	return &ExpiringSet{
+		ttl:  int64(ttl / Second),
+		seen: make(map[string]Time32),
+	}
+}
+
+// Add adds key to the set. It returns false if key was already added less
+// than ttl ago, in which case its insertion time is not refreshed.
+// Expired keys are evicted lazily, on Add.
+func (s *ExpiringSet) Add(key string) bool {
+	now := loadSnapshot().epoch
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.evict(now)
+	if _, ok := s.seen[key]; ok {
+		return false
+	}
+	s.seen[key] = now
+	s.order = append(s.order, expiringEntry{key: key, at: now})
+	return true
+}
+
+// Len returns the number of keys in the set, including expired keys that
+// have not been evicted yet.
+func (s *ExpiringSet) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.seen)
+}
+
+// evict removes every key added ttl or more before now. s.mu must be held.
+func (s *ExpiringSet) evict(now Time32) {
+	n := 0
+	for n < len(s.order) && int64(now)-int64(s.order[n].at) >= s.ttl {
+		delete(s.seen, s.order[n].key)
+		n++
+	}
+	if n > 0 {
+		s.order = append(s.order[:0], s.order[n:]...)
+	}
+}
@@ -0,0 +1,49 @@
+//
+// Created by zerjioang
+// https://github/zerjioang
+// Copyright (c) 2020. All rights reserved.
+//
+// SPDX-License-Identifier: GPL-3.0
+//
+
+package time32
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestExpiringSet(t *testing.T) {
+	t.Run("duplicate-within-ttl", func(t *testing.T) {
+		clock := NewManualClock(time.Unix(1588228661, 0))
+		SetClock(clock)
+		defer SetClock(nil)
+		s := NewExpiringSet(10 * Second)
+		assert.True(t, s.Add("a"))
+		assert.False(t, s.Add("a"))
+		clock.Advance(9 * time.Second)
+		assert.False(t, s.Add("a"))
+		assert.True(t, s.Add("b"))
+		assert.Equal(t, 2, s.Len())
+	})
+	t.Run("new-after-ttl", func(t *testing.T) {
+		clock := NewManualClock(time.Unix(1588228661, 0))
+		SetClock(clock)
+		defer SetClock(nil)
+		s := NewExpiringSet(10 * Second)
+		assert.True(t, s.Add("a"))
+		clock.Advance(5 * time.Second)
+		assert.True(t, s.Add("b"))
+		clock.Advance(5 * time.Second)
+		assert.True(t, s.Add("a"))
+		assert.False(t, s.Add("b"))
+		assert.Equal(t, 2, s.Len())
+		clock.Advance(time.Hour)
+		assert.True(t, s.Add("c"))
+		assert.Equal(t, 1, s.Len())
+	})
+	t.Run("invalid-ttl", func(t *testing.T) {
+		assert.Panics(t, func() { NewExpiringSet(Millisecond) })
+	})
+}
@@ -0,0 +1,20 @@
+//
+// Created by zerjioang
+// https://github/zerjioang
+// Copyright (c) 2020. All rights reserved.
+//
+// SPDX-License-Identifier: GPL-3.0
+//
+
+package time32
+
+import (
+	"strconv"
+)
+
+// ExpvarString returns t as a JSON number, the form expected from the
+// String method of an expvar.Var. Publishing helpers live in the
+// time32expvar package, so importing time32 does not import expvar.
+func (t Time32) ExpvarString() string {
+	return strconv.FormatUint(uint64(t), 10)
+}
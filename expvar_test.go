@@ -0,0 +1,21 @@
+//
+// Created by zerjioang
+// https://github/zerjioang
+// Copyright (c) 2020. All rights reserved.
+//
+// SPDX-License-Identifier: GPL-3.0
+//
+
+package time32
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestExpvar(t *testing.T) {
+	t.Run("expvar-string", func(t *testing.T) {
+		assert.Equal(t, "1588228661", Time32(1588228661).ExpvarString())
+		assert.Equal(t, "0", Time32(0).ExpvarString())
+	})
+}
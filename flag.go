@@ -0,0 +1,40 @@
+//
+// Created by zerjioang
+// https://github/zerjioang
+// Copyright (c) 2020. All rights reserved.
+//
+// SPDX-License-Identifier: GPL-3.0
+//
+
+package time32
+
+import (
+	"flag"
+)
+
+// Set implements flag.Value, parsing s either as an integer epoch value
+// or as an RFC3339 timestamp. Together with String it lets a *Time32 be
+// registered with flag.Var.
+func (t *Time32) Set(s string) error {
+	v, err := parseEpochOrRFC3339(s)
+	if err != nil {
+		return err
+	}
+	*t = v
+	return nil
+}
+
+// Time32Var defines a Time32 flag with the specified name, default value
+// and usage string on flag.CommandLine. The argument p points to a Time32
+// variable in which to store the value of the flag. It mirrors
+// flag.IntVar and accepts integer epochs or RFC3339 timestamps.
+func Time32Var(p *Time32, name string, value Time32, usage string) {
+	FlagSetTime32Var(flag.CommandLine, p, name, value, usage)
+}
+
+// FlagSetTime32Var is like Time32Var, but defines the flag on fs instead of
+// flag.CommandLine.
+func FlagSetTime32Var(fs *flag.FlagSet, p *Time32, name string, value Time32, usage string) {
+	*p = value
+	fs.Var(p, name, usage)
+}
@@ -0,0 +1,62 @@
+//
+// Created by zerjioang
+// https://github/zerjioang
+// Copyright (c) 2020. All rights reserved.
+//
+// SPDX-License-Identifier: GPL-3.0
+//
+
+package time32
+
+import (
+	"flag"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"strconv"
+	"sync/atomic"
+	"testing"
+)
+
+func TestFlag(t *testing.T) {
+	t.Run("flag-set", func(t *testing.T) {
+		fs := flag.NewFlagSet("test", flag.ContinueOnError)
+		var since, until Time32
+		fs.Var(&since, "since", "start time")
+		fs.Var(&until, "until", "end time")
+		err := fs.Parse([]string{"--since", "2020-04-30T06:37:41Z", "--until=1588228700"})
+		assert.NoError(t, err)
+		assert.Equal(t, Time32(1588228661), since)
+		assert.Equal(t, Time32(1588228700), until)
+	})
+	t.Run("invalid", func(t *testing.T) {
+		fs := flag.NewFlagSet("test", flag.ContinueOnError)
+		fs.SetOutput(io.Discard)
+		var since Time32
+		fs.Var(&since, "since", "start time")
+		assert.Error(t, fs.Parse([]string{"--since", "yesterday"}))
+		assert.Error(t, fs.Parse([]string{"--since", "-1"}))
+	})
+	t.Run("flag-set-time32-var", func(t *testing.T) {
+		fs := flag.NewFlagSet("test", flag.ContinueOnError)
+		var since Time32
+		FlagSetTime32Var(fs, &since, "since", 42, "start time")
+		assert.Equal(t, Time32(42), since)
+		assert.Equal(t, "1970-01-01T00:00:42Z", fs.Lookup("since").DefValue)
+		assert.NoError(t, fs.Parse([]string{"--since", "1588228661"}))
+		assert.Equal(t, Time32(1588228661), since)
+	})
+	t.Run("time32-var", func(t *testing.T) {
+		// flag.CommandLine is process wide, so use a new name on every
+		// run to support go test -count
+		name := "time32-test-since-" + strconv.Itoa(int(atomic.AddInt32(&flagTestRuns, 1)))
+		var since Time32
+		Time32Var(&since, name, 42, "start time")
+		assert.Equal(t, Time32(42), since)
+		assert.NotNil(t, flag.Lookup(name))
+		assert.NoError(t, flag.Set(name, "1588228661"))
+		assert.Equal(t, Time32(1588228661), since)
+	})
+}
+
+// flagTestRuns counts the runs of the time32-var test
+var flagTestRuns int32
@@ -0,0 +1,272 @@
+//
+// Created by zerjioang
+// https://github/zerjioang
+// Copyright (c) 2020. All rights reserved.
+//
+// SPDX-License-Identifier: GPL-3.0
+//
+
+package time32
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrLeapSecond is returned when a timestamp refers to a leap second
+// (a seconds field of 60), which can not be represented by a Time32.
+var ErrLeapSecond = errors.New("time32: leap seconds are not representable")
+
+// String returns t formatted as RFC3339 in UTC, e.g. "2020-04-30T06:37:41Z".
+// It makes Time32 values print as dates in fmt and text/template output.
+func (t Time32) String() string {
+	return t.Format("2006-01-02T15:04:05Z07:00")
+}
+
+// FormatDate returns t formatted as "YYYY-MM-DD" in UTC.
+// It is meant to be called from templates as {{.When.FormatDate}}.
+func (t Time32) FormatDate() string {
+	return t.DateString()
+}
+
+// FormatDateTime returns t formatted as "YYYY-MM-DD HH:MM:SS" in UTC.
+// It is meant to be called from templates as {{.When.FormatDateTime}}.
+func (t Time32) FormatDateTime() string {
+	return t.Format("2006-01-02 15:04:05")
+}
+
+// DateString returns t formatted as "2006-01-02" in UTC. It builds the
+// string directly, which is cheaper than Format with the same layout.
+func (t Time32) DateString() string {
+	year, month, day, _ := absDate(t.abs(), true)
+	b := [10]byte{'0', '0', '0', '0', '-', '0', '0', '-', '0', '0'}
+	put2(b[0:], year/100)
+	put2(b[2:], year%100)
+	put2(b[5:], int(month))
+	put2(b[8:], day)
+	return string(b[:])
+}
+
+// TimeString returns the time of day of t formatted as "15:04:05" in UTC.
+// It builds the string directly, which is cheaper than Format with the
+// same layout.
+func (t Time32) TimeString() string {
+	hour, min, sec := absClock(t.abs())
+	b := [8]byte{'0', '0', ':', '0', '0', ':', '0', '0'}
+	put2(b[0:], hour)
+	put2(b[3:], min)
+	put2(b[6:], sec)
+	return string(b[:])
+}
+
+// put2 writes the two digit decimal v, 0 <= v < 100, to b[0] and b[1].
+func put2(b []byte, v int) {
+	b[0] = byte('0' + v/10)
+	b[1] = byte('0' + v%10)
+}
+
+// SyslogTimestamp returns t formatted as an RFC5424 syslog timestamp in
+// UTC, e.g. "2020-04-30T06:37:41.000000Z". Time32 has a one second
+// resolution, so the microsecond fraction is always ".000000"; it is only
+// there for forwarders expecting that fixed-width form.
+func (t Time32) SyslogTimestamp() string {
+	return t.Format("2006-01-02T15:04:05.000000Z")
+}
+
+// PaddedString returns the epoch value of t as a 10 digit, zero padded
+// decimal string (e.g. "0000000042"), so that sorting the strings
+// lexicographically matches sorting the values numerically.
+func (t Time32) PaddedString() string {
+	var b [10]byte
+	v := uint32(t)
+	for i := len(b) - 1; i >= 0; i-- {
+		b[i] = byte('0' + v%10)
+		v /= 10
+	}
+	return string(b[:])
+}
+
+// Format returns a textual representation of t in UTC, formatted according
+// to layout. Layouts are the ones defined by the standard time package,
+// e.g. time.RFC3339 or time.ANSIC, including the space padded day "_2"
+// and the "Mon"/"Jan" abbreviations, and the output matches the one of
+// the standard library for the same instant in UTC.
+func (t Time32) Format(layout string) string {
+	const bufSize = 64
+	var b []byte
+	max := len(layout) + 10
+	if max < bufSize {
+		var buf [bufSize]byte
+		b = buf[:0]
+	} else {
+		b = make([]byte, 0, max)
+	}
+	b = t.AppendFormat(b, layout)
+	return string(b)
+}
+
+// AppendFormat is like Format but appends the textual representation to b
+// and returns the extended buffer.
+func (t Time32) AppendFormat(b []byte, layout string) []byte {
+	return t.ToTime().AppendFormat(b, layout)
+}
+
+// FormatTo appends the textual representation of t, formatted according to
+// layout, to the slice pointed by buf. It fits the *[]byte reuse idiom, so
+// buffers can be recycled through a sync.Pool to avoid allocating a new
+// string on every call:
+//
+//	var pool = sync.Pool{New: func() interface{} {
+//		b := make([]byte, 0, 64)
+//		return &b
+//	}}
+//
+//	buf := pool.Get().(*[]byte)
+//	*buf = (*buf)[:0]
+//	t.FormatTo(buf, time.RFC3339)
+//	w.Write(*buf)
+//	pool.Put(buf)
+func (t Time32) FormatTo(buf *[]byte, layout string) {
+	*buf = t.AppendFormat(*buf, layout)
+}
+
+// ParseTime32 parses an RFC3339 timestamp into a Time32, truncating any
+// fractional seconds. Surrounding whitespace is ignored and the lowercase
+// 't' and 'z' designators are accepted. Numeric offsets such as "+05:30"
+// are accepted too, and the result is normalized to UTC.
+// It returns ErrOutOfRange if the instant does not fit in a Time32.
+func ParseTime32(s string) (Time32, error) {
+	s = strings.TrimSpace(s)
+	if n := len(s); n > 10 && (s[10] == 't' || s[n-1] == 'z') {
+		b := []byte(s)
+		if b[10] == 't' {
+			b[10] = 'T'
+		}
+		if b[n-1] == 'z' {
+			b[n-1] = 'Z'
+		}
+		s = string(b)
+	}
+	v, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return 0, err
+	}
+	return fromUnix(v.Unix())
+}
+
+// parseEpochOrRFC3339 parses s as a decimal epoch value if it is an
+// integer, or as an RFC3339 timestamp otherwise.
+func parseEpochOrRFC3339(s string) (Time32, error) {
+	if v, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return fromUnix(v)
+	}
+	return ParseTime32(s)
+}
+
+// ValidateNoLeapSecond parses s as an RFC3339 timestamp and returns an
+// error if it is malformed or if it refers to a leap second such as
+// "2016-12-31T23:59:60Z". Time32 follows the package's no-leap-seconds
+// model, so such inputs must be rejected (or folded by the caller) before
+// converting them.
+func ValidateNoLeapSecond(s string) error {
+	_, err := time.Parse(time.RFC3339, s)
+	if err != nil && len(s) >= 19 && s[17:19] == "60" {
+		// time.Parse rejects second 60: report a leap second only if s
+		// is otherwise valid
+		if _, perr := time.Parse(time.RFC3339, s[:17]+"59"+s[19:]); perr == nil {
+			return ErrLeapSecond
+		}
+	}
+	return err
+}
+
+// RFC3339Error describes the first invalid character found by ValidateRFC3339.
+type RFC3339Error struct {
+	// Pos is the byte offset of the first invalid character. It is equal
+	// to the input length when the input is too short.
+	Pos int
+	// Expected describes what was expected at Pos.
+	Expected string
+}
+
+// Error implements the error interface.
+func (e *RFC3339Error) Error() string {
+	return "time32: expected " + e.Expected + " at position " + strconv.Itoa(e.Pos)
+}
+
+// ValidateRFC3339 checks that s is a syntactically valid RFC3339 timestamp,
+// such as "2020-04-30T06:37:41Z" or "2020-04-30T06:37:41.5+05:30", without
+// building a time.Time. On failure it returns an *RFC3339Error naming the
+// position of the first invalid character and what was expected there.
+// Field ranges (e.g. month 13) are not checked.
+func ValidateRFC3339(s string) error {
+	i, err := matchLayout(s, 0, "dddd-dd-ddTdd:dd:dd")
+	if err != nil {
+		return err
+	}
+	if i < len(s) && s[i] == '.' {
+		i++
+		start := i
+		for i < len(s) && '0' <= s[i] && s[i] <= '9' {
+			i++
+		}
+		if i == start {
+			return &RFC3339Error{Pos: i, Expected: "digit"}
+		}
+	}
+	if i == len(s) {
+		return &RFC3339Error{Pos: i, Expected: "'Z' or numeric offset"}
+	}
+	switch s[i] {
+	case 'Z', 'z':
+		i++
+	case '+', '-':
+		if i, err = matchLayout(s, i+1, "dd:dd"); err != nil {
+			return err
+		}
+	default:
+		return &RFC3339Error{Pos: i, Expected: "'Z' or numeric offset"}
+	}
+	if i != len(s) {
+		return &RFC3339Error{Pos: i, Expected: "end of input"}
+	}
+	return nil
+}
+
+// matchLayout matches s, starting at position i, against layout, where 'd'
+// stands for any digit, 'T' for a 'T' or 't' separator and any other byte
+// for itself. It returns the position following the match.
+func matchLayout(s string, i int, layout string) (int, error) {
+	for j := 0; j < len(layout); j, i = j+1, i+1 {
+		if i >= len(s) {
+			return i, layoutError(i, layout[j])
+		}
+		c := s[i]
+		switch layout[j] {
+		case 'd':
+			if c < '0' || c > '9' {
+				return i, layoutError(i, layout[j])
+			}
+		case 'T':
+			if c != 'T' && c != 't' {
+				return i, layoutError(i, layout[j])
+			}
+		default:
+			if c != layout[j] {
+				return i, layoutError(i, layout[j])
+			}
+		}
+	}
+	return i, nil
+}
+
+// layoutError returns the error reported when the layout byte l is not
+// matched at position pos.
+func layoutError(pos int, l byte) error {
+	if l == 'd' {
+		return &RFC3339Error{Pos: pos, Expected: "digit"}
+	}
+	return &RFC3339Error{Pos: pos, Expected: "'" + string(l) + "'"}
+}
@@ -0,0 +1,192 @@
+//
+// Created by zerjioang
+// https://github/zerjioang
+// Copyright (c) 2020. All rights reserved.
+//
+// SPDX-License-Identifier: GPL-3.0
+//
+
+package time32
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"text/template"
+	"time"
+)
+
+func TestFormat(t *testing.T) {
+	t.Run("format", func(t *testing.T) {
+		tt := Time32(1588228661)
+		assert.Equal(t, "2020-04-30T06:37:41Z", tt.Format(time.RFC3339))
+	})
+	t.Run("padded-layouts", func(t *testing.T) {
+		layout := "Mon Jan _2 15:04:05 2006"
+		for _, v := range []Time32{0, 1583020800, 1588228661, 1609459199, math.MaxUint32} {
+			expected := time.Unix(int64(v), 0).UTC().Format(layout)
+			assert.Equal(t, expected, v.Format(layout))
+		}
+		// 2020-03-01T00:00:00Z
+		assert.Equal(t, "Sun Mar  1 00:00:00 2020", Time32(1583020800).Format(layout))
+		assert.Equal(t, "Thu Apr 30 06:37:41 2020", Time32(1588228661).Format(time.ANSIC))
+	})
+	t.Run("date-and-time-string", func(t *testing.T) {
+		for _, v := range []Time32{0, 1588228661, 951782400, 4102444799, math.MaxUint32} {
+			std := time.Unix(int64(v), 0).UTC()
+			assert.Equal(t, std.Format("2006-01-02"), v.DateString())
+			assert.Equal(t, std.Format("15:04:05"), v.TimeString())
+			assert.Equal(t, v.DateString(), v.FormatDate())
+		}
+		assert.Equal(t, "2020-04-30", Time32(1588228661).DateString())
+		assert.Equal(t, "06:37:41", Time32(1588228661).TimeString())
+	})
+	t.Run("syslog-timestamp", func(t *testing.T) {
+		assert.Equal(t, "2020-04-30T06:37:41.000000Z", Time32(1588228661).SyslogTimestamp())
+		assert.Equal(t, "1970-01-01T00:00:00.000000Z", Time32(0).SyslogTimestamp())
+	})
+	t.Run("padded-string", func(t *testing.T) {
+		assert.Equal(t, "1588228661", Time32(1588228661).PaddedString())
+		assert.Equal(t, "0000000042", Time32(42).PaddedString())
+		assert.Equal(t, "4294967295", Time32(math.MaxUint32).PaddedString())
+
+		values := []Time32{math.MaxUint32, 7, 1588228661, 0, 99, 100000, 1000000000, 999999999}
+		strs := make([]string, len(values))
+		for i, v := range values {
+			strs[i] = v.PaddedString()
+		}
+		sort.Slice(values, func(i, j int) bool { return values[i] < values[j] })
+		sort.Strings(strs)
+		for i, v := range values {
+			assert.Equal(t, v.PaddedString(), strs[i])
+		}
+	})
+	t.Run("format-to", func(t *testing.T) {
+		tt := Time32(1588228661)
+		buf := []byte("at ")
+		tt.FormatTo(&buf, time.RFC3339)
+		assert.Equal(t, "at 2020-04-30T06:37:41Z", string(buf))
+	})
+}
+
+func TestParseTime32(t *testing.T) {
+	t.Run("rfc3339", func(t *testing.T) {
+		v, err := ParseTime32("2020-04-30T06:37:41.9Z")
+		assert.NoError(t, err)
+		assert.Equal(t, Time32(1588228661), v)
+	})
+	t.Run("surrounding-whitespace", func(t *testing.T) {
+		v, err := ParseTime32(" 2020-04-30T06:37:41Z \n")
+		assert.NoError(t, err)
+		assert.Equal(t, Time32(1588228661), v)
+	})
+	t.Run("lowercase-designators", func(t *testing.T) {
+		v, err := ParseTime32("2020-04-30T06:37:41z")
+		assert.NoError(t, err)
+		assert.Equal(t, Time32(1588228661), v)
+		v, err = ParseTime32("2020-04-30t06:37:41z")
+		assert.NoError(t, err)
+		assert.Equal(t, Time32(1588228661), v)
+	})
+	t.Run("offset-normalized", func(t *testing.T) {
+		v, err := ParseTime32("2020-04-30T12:07:41+05:30")
+		assert.NoError(t, err)
+		assert.Equal(t, Time32(1588228661), v)
+	})
+	t.Run("out-of-range", func(t *testing.T) {
+		_, err := ParseTime32("1969-12-31T23:59:59Z")
+		assert.Equal(t, ErrOutOfRange, err)
+	})
+}
+
+func TestValidateNoLeapSecond(t *testing.T) {
+	t.Run("normal", func(t *testing.T) {
+		assert.NoError(t, ValidateNoLeapSecond("2016-12-31T23:59:59Z"))
+		assert.NoError(t, ValidateNoLeapSecond("2016-12-31T23:59:59.5+01:00"))
+	})
+	t.Run("leap-second", func(t *testing.T) {
+		assert.Equal(t, ErrLeapSecond, ValidateNoLeapSecond("2016-12-31T23:59:60Z"))
+	})
+	t.Run("malformed", func(t *testing.T) {
+		assert.Error(t, ValidateNoLeapSecond("2016-12-31 23:59"))
+		err := ValidateNoLeapSecond("2016-12-31T23:59:60garbage")
+		assert.Error(t, err)
+		assert.NotEqual(t, ErrLeapSecond, err)
+		assert.NotEqual(t, ErrLeapSecond, ValidateNoLeapSecond("2016-13-31T23:59:60Z"))
+	})
+}
+
+func TestValidateRFC3339(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		assert.NoError(t, ValidateRFC3339("2020-04-30T06:37:41Z"))
+		assert.NoError(t, ValidateRFC3339("2020-04-30t06:37:41.123z"))
+		assert.NoError(t, ValidateRFC3339("2020-04-30T06:37:41+05:30"))
+		assert.Equal(t, 0.0, testing.AllocsPerRun(10, func() {
+			_ = ValidateRFC3339("2020-04-30T06:37:41+05:30")
+		}))
+	})
+	t.Run("wrong-separator", func(t *testing.T) {
+		err := ValidateRFC3339("2020/04/30T06:37:41Z")
+		assert.EqualError(t, err, "time32: expected '-' at position 4")
+		assert.Equal(t, 4, err.(*RFC3339Error).Pos)
+	})
+	t.Run("too-short", func(t *testing.T) {
+		err := ValidateRFC3339("2020-04-30T06:37")
+		assert.EqualError(t, err, "time32: expected ':' at position 16")
+		assert.Equal(t, 16, err.(*RFC3339Error).Pos)
+	})
+	t.Run("bad-offset", func(t *testing.T) {
+		err := ValidateRFC3339("2020-04-30T06:37:41+0530")
+		assert.EqualError(t, err, "time32: expected ':' at position 22")
+		err = ValidateRFC3339("2020-04-30T06:37:41")
+		assert.EqualError(t, err, "time32: expected 'Z' or numeric offset at position 19")
+		err = ValidateRFC3339("2020-04-30T06:37:41Zx")
+		assert.EqualError(t, err, "time32: expected end of input at position 20")
+	})
+}
+
+func TestTemplate(t *testing.T) {
+	t.Run("text-template", func(t *testing.T) {
+		data := struct{ When Time32 }{When: Time32(1588228661)}
+		tpl := template.Must(template.New("t").Parse("{{.When}}|{{.When.FormatDate}}|{{.When.FormatDateTime}}"))
+		var out strings.Builder
+		assert.NoError(t, tpl.Execute(&out, data))
+		assert.Equal(t, "2020-04-30T06:37:41Z|2020-04-30|2020-04-30 06:37:41", out.String())
+	})
+	t.Run("stringer", func(t *testing.T) {
+		var s fmt.Stringer = Time32(0)
+		assert.Equal(t, "1970-01-01T00:00:00Z", s.String())
+	})
+}
+
+func BenchmarkFormat(b *testing.B) {
+	b.Run("format-to-pool", func(b *testing.B) {
+		pool := sync.Pool{New: func() interface{} {
+			buf := make([]byte, 0, 64)
+			return &buf
+		}}
+		tt := Time32(1588228661)
+		b.ReportAllocs()
+		b.SetBytes(1)
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			buf := pool.Get().(*[]byte)
+			*buf = (*buf)[:0]
+			tt.FormatTo(buf, time.RFC3339)
+			pool.Put(buf)
+		}
+	})
+	b.Run("format", func(b *testing.B) {
+		tt := Time32(1588228661)
+		b.ReportAllocs()
+		b.SetBytes(1)
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			_ = tt.Format(time.RFC3339)
+		}
+	})
+}
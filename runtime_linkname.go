@@ -0,0 +1,32 @@
+//
+// Created by zerjioang
+// https://github/zerjioang
+// Copyright (c) 2020. All rights reserved.
+//
+// SPDX-License-Identifier: GPL-3.0
+//
+
+//go:build !time32_nolinkname
+
+package time32
+
+import (
+	_ "unsafe" // for go:linkname
+)
+
+// usingLinkname reports that the runtime clocks are reached through
+// go:linkname. Build with the time32_nolinkname tag to disable it.
+const usingLinkname = true
+
+//go:noescape
+//go:linkname nanotime runtime.nanotime
+func nanotime() int64
+
+//go:noescape
+//go:linkname time_now time.now
+func time_now() (sec int64, nsec int32, mono int64)
+
+// runtimeNano returns the current value of the runtime clock in nanoseconds.
+//
+//go:linkname runtimeNano runtime.nanotime
+func runtimeNano() int64
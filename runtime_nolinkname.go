@@ -0,0 +1,38 @@
+//
+// Created by zerjioang
+// https://github/zerjioang
+// Copyright (c) 2020. All rights reserved.
+//
+// SPDX-License-Identifier: GPL-3.0
+//
+
+//go:build time32_nolinkname
+
+package time32
+
+import (
+	"time"
+)
+
+// usingLinkname reports that the runtime clocks are reached through
+// go:linkname. This build uses the time.Now based fallback instead, for
+// toolchains or linker flags that reject pulling runtime symbols.
+const usingLinkname = false
+
+// monoBase is the reference the fallback monotonic readings are taken
+// from. It is never reported as 0 because startNano is one below it.
+var monoBase = time.Now()
+
+func nanotime() int64 {
+	return int64(time.Since(monoBase))
+}
+
+func time_now() (sec int64, nsec int32, mono int64) {
+	t := time.Now()
+	return t.Unix(), int32(t.Nanosecond()), int64(t.Sub(monoBase))
+}
+
+// runtimeNano returns the current value of the monotonic clock in nanoseconds.
+func runtimeNano() int64 {
+	return nanotime()
+}
@@ -0,0 +1,143 @@
+//
+// Created by zerjioang
+// https://github/zerjioang
+// Copyright (c) 2020. All rights reserved.
+//
+// SPDX-License-Identifier: GPL-3.0
+//
+
+package time32
+
+import (
+	"math/rand"
+	"sort"
+	"sync"
+)
+
+// SearchAtOrAfter returns the index of the first element of ts that is at
+// or after target, or len(ts) if there is none. ts must be sorted in
+// ascending order.
+func SearchAtOrAfter(ts []Time32, target Time32) int {
+	return sort.Search(len(ts), func(i int) bool {
+		return int64(ts[i]) >= int64(target)
+	})
+}
+
+// SearchBefore returns the index of the last element of ts that is before
+// target, or -1 if there is none. ts must be sorted in ascending order.
+func SearchBefore(ts []Time32, target Time32) int {
+	return SearchAtOrAfter(ts, target) - 1
+}
+
+// Earliest returns the earliest of ts. The boolean is false if ts is empty.
+func Earliest(ts ...Time32) (Time32, bool) {
+	if len(ts) == 0 {
+		return 0, false
+	}
+	min := ts[0]
+	for _, t := range ts[1:] {
+		if t.Compare(min) < 0 {
+			min = t
+		}
+	}
+	return min, true
+}
+
+// Latest returns the latest of ts. The boolean is false if ts is empty.
+func Latest(ts ...Time32) (Time32, bool) {
+	if len(ts) == 0 {
+		return 0, false
+	}
+	max := ts[0]
+	for _, t := range ts[1:] {
+		if t.Compare(max) > 0 {
+			max = t
+		}
+	}
+	return max, true
+}
+
+// OrderWithin returns a sort key for each element of ts. The high 32 bits
+// of a key hold the Time32 and the low 32 bits the number of earlier
+// elements of ts within the same second, so sorting the keys orders events
+// by time and keeps the input order among events sharing a second.
+func OrderWithin(ts []Time32) []uint64 {
+	keys := make([]uint64, len(ts))
+	seen := make(map[Time32]uint32, len(ts))
+	for i, t := range ts {
+		keys[i] = uint64(t)<<32 | uint64(seen[t])
+		seen[t]++
+	}
+	return keys
+}
+
+// RandTime32 returns a uniformly distributed random Time32 in [min, max],
+// drawn from r so that fixtures are reproducible from a seed.
+// It panics if min > max.
+func RandTime32(r *rand.Rand, min, max Time32) Time32 {
+	if min > max {
+		panic("time32: min after max in RandTime32")
+	}
+	return min + Time32(r.Int63n(int64(max-min)+1))
+}
+
+// Time32Buffer is a pool of reusable []Time32 batches, reducing garbage
+// in ingestion loops that build a slice per batch. The zero value is ready
+// to use and is safe for concurrent use.
+//
+// A slice obtained with Get belongs to the caller until it is handed back
+// with Put. After Put the caller must not keep or use it, nor any subslice
+// of it, since its backing array will be handed out again.
+type Time32Buffer struct {
+	pool sync.Pool
+}
+
+// defaultBufferCap is the capacity of slices newly allocated by Get
+const defaultBufferCap = 1024
+
+// Get returns an empty slice, reusing the backing array of a slice
+// previously returned with Put when one is available.
+func (b *Time32Buffer) Get() *[]Time32 {
+	if p, ok := b.pool.Get().(*[]Time32); ok {
+		return p
+	}
+	s := make([]Time32, 0, defaultBufferCap)
+	return &s
+}
+
+// Put resets the length of *p to zero and makes it available to Get.
+func (b *Time32Buffer) Put(p *[]Time32) {
+	*p = (*p)[:0]
+	b.pool.Put(p)
+}
+
+// TimeHeap is a min-heap of Time32 values implementing heap.Interface, so
+// the earliest time is always at index 0. Use it through container/heap:
+//
+//	h := &time32.TimeHeap{}
+//	heap.Push(h, t)
+//	earliest := heap.Pop(h).(time32.Time32)
+type TimeHeap []Time32
+
+// Len implements sort.Interface.
+func (h TimeHeap) Len() int { return len(h) }
+
+// Less implements sort.Interface.
+func (h TimeHeap) Less(i, j int) bool { return int64(h[i]) < int64(h[j]) }
+
+// Swap implements sort.Interface.
+func (h TimeHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+
+// Push implements heap.Interface. x must be a Time32.
+func (h *TimeHeap) Push(x interface{}) {
+	*h = append(*h, x.(Time32))
+}
+
+// Pop implements heap.Interface.
+func (h *TimeHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	x := old[n-1]
+	*h = old[:n-1]
+	return x
+}
@@ -0,0 +1,188 @@
+//
+// Created by zerjioang
+// https://github/zerjioang
+// Copyright (c) 2020. All rights reserved.
+//
+// SPDX-License-Identifier: GPL-3.0
+//
+
+package time32
+
+import (
+	"container/heap"
+	"github.com/stretchr/testify/assert"
+	"math"
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+func TestSearch(t *testing.T) {
+	ts := []Time32{10, 20, 30, 40}
+	t.Run("before-all", func(t *testing.T) {
+		assert.Equal(t, 0, SearchAtOrAfter(ts, 5))
+		assert.Equal(t, -1, SearchBefore(ts, 5))
+	})
+	t.Run("after-all", func(t *testing.T) {
+		assert.Equal(t, 4, SearchAtOrAfter(ts, 50))
+		assert.Equal(t, 3, SearchBefore(ts, 50))
+	})
+	t.Run("exact-match", func(t *testing.T) {
+		assert.Equal(t, 2, SearchAtOrAfter(ts, 30))
+		assert.Equal(t, 1, SearchBefore(ts, 30))
+	})
+	t.Run("between-elements", func(t *testing.T) {
+		assert.Equal(t, 2, SearchAtOrAfter(ts, 25))
+		assert.Equal(t, 1, SearchBefore(ts, 25))
+	})
+	t.Run("empty", func(t *testing.T) {
+		assert.Equal(t, 0, SearchAtOrAfter(nil, 25))
+		assert.Equal(t, -1, SearchBefore(nil, 25))
+	})
+}
+
+func TestEarliestLatest(t *testing.T) {
+	t.Run("compare", func(t *testing.T) {
+		assert.Equal(t, -1, Time32(1).Compare(2))
+		assert.Equal(t, 0, Time32(2).Compare(2))
+		assert.Equal(t, 1, Time32(3).Compare(2))
+	})
+	t.Run("empty", func(t *testing.T) {
+		_, ok := Earliest()
+		assert.False(t, ok)
+		_, ok = Latest()
+		assert.False(t, ok)
+	})
+	t.Run("single", func(t *testing.T) {
+		e, ok := Earliest(42)
+		assert.True(t, ok)
+		assert.Equal(t, Time32(42), e)
+		l, ok := Latest(42)
+		assert.True(t, ok)
+		assert.Equal(t, Time32(42), l)
+	})
+	t.Run("multiple", func(t *testing.T) {
+		ts := []Time32{1588228661, 0, 4294967295, 86400}
+		e, ok := Earliest(ts...)
+		assert.True(t, ok)
+		assert.Equal(t, Time32(0), e)
+		l, ok := Latest(ts...)
+		assert.True(t, ok)
+		assert.Equal(t, Time32(4294967295), l)
+	})
+}
+
+func TestOrderWithin(t *testing.T) {
+	t.Run("stable-keys", func(t *testing.T) {
+		ts := []Time32{20, 10, 20, 10, 20, 5}
+		keys := OrderWithin(ts)
+		assert.Equal(t, []uint64{20 << 32, 10 << 32, 20<<32 | 1, 10<<32 | 1, 20<<32 | 2, 5 << 32}, keys)
+
+		idx := []int{0, 1, 2, 3, 4, 5}
+		sort.Slice(idx, func(i, j int) bool { return keys[idx[i]] < keys[idx[j]] })
+		assert.Equal(t, []int{5, 1, 3, 0, 2, 4}, idx)
+		for i, k := range keys {
+			assert.Equal(t, ts[i], Time32(k>>32))
+		}
+	})
+	t.Run("empty", func(t *testing.T) {
+		assert.Empty(t, OrderWithin(nil))
+	})
+}
+
+func TestRandTime32(t *testing.T) {
+	t.Run("reproducible", func(t *testing.T) {
+		a := rand.New(rand.NewSource(42))
+		b := rand.New(rand.NewSource(42))
+		min, max := Time32(1588228661), Time32(1588228661+3600)
+		for i := 0; i < 1000; i++ {
+			v := RandTime32(a, min, max)
+			assert.Equal(t, v, RandTime32(b, min, max))
+			assert.True(t, min <= v && v <= max)
+		}
+	})
+	t.Run("bounds", func(t *testing.T) {
+		r := rand.New(rand.NewSource(1))
+		assert.Equal(t, Time32(7), RandTime32(r, 7, 7))
+		for i := 0; i < 1000; i++ {
+			_ = RandTime32(r, 0, math.MaxUint32)
+		}
+		assert.Panics(t, func() { RandTime32(r, 2, 1) })
+	})
+}
+
+func TestTime32Buffer(t *testing.T) {
+	t.Run("reuse", func(t *testing.T) {
+		var buf Time32Buffer
+		// sync.Pool may drop items at random (it always does so some of
+		// the time under the race detector), so retry a few cycles
+		reused := false
+		for i := 0; i < 100 && !reused; i++ {
+			p := buf.Get()
+			*p = append(*p, 1, 2, 3)
+			first := &(*p)[0]
+			buf.Put(p)
+			assert.Len(t, *p, 0)
+
+			q := buf.Get()
+			assert.Len(t, *q, 0)
+			*q = append(*q, 4)
+			reused = &(*q)[0] == first
+			buf.Put(q)
+		}
+		assert.True(t, reused)
+	})
+	t.Run("zero-value", func(t *testing.T) {
+		var buf Time32Buffer
+		p := buf.Get()
+		assert.Len(t, *p, 0)
+		assert.True(t, cap(*p) > 0)
+	})
+}
+
+func BenchmarkTime32Buffer(b *testing.B) {
+	const batch = 512
+	b.Run("pooled", func(b *testing.B) {
+		var buf Time32Buffer
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			p := buf.Get()
+			for j := 0; j < batch; j++ {
+				*p = append(*p, Time32(j))
+			}
+			buf.Put(p)
+		}
+	})
+	b.Run("make", func(b *testing.B) {
+		var sink []Time32
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			s := make([]Time32, 0, defaultBufferCap)
+			for j := 0; j < batch; j++ {
+				s = append(s, Time32(j))
+			}
+			sink = s
+		}
+		_ = sink
+	})
+}
+
+func TestTimeHeap(t *testing.T) {
+	t.Run("pops-ascending", func(t *testing.T) {
+		r := rand.New(rand.NewSource(1))
+		values := make([]Time32, 100)
+		for i := range values {
+			values[i] = Time32(1588228661 + i)
+		}
+		r.Shuffle(len(values), func(i, j int) { values[i], values[j] = values[j], values[i] })
+
+		h := &TimeHeap{}
+		for _, v := range values {
+			heap.Push(h, v)
+		}
+		for i := 0; i < 100; i++ {
+			assert.Equal(t, Time32(1588228661+i), heap.Pop(h).(Time32))
+		}
+		assert.Equal(t, 0, h.Len())
+	})
+}
@@ -0,0 +1,20 @@
+//
+// Created by zerjioang
+// https://github/zerjioang
+// Copyright (c) 2020. All rights reserved.
+//
+// SPDX-License-Identifier: GPL-3.0
+//
+
+//go:build go1.21
+
+package time32
+
+import "log/slog"
+
+// LogValue implements the slog.LogValuer interface, so structured logs
+// render a Time32 as a time instead of an integer. It is only available
+// when building with Go 1.21 or newer.
+func (t Time32) LogValue() slog.Value {
+	return slog.TimeValue(t.ToTime())
+}
@@ -0,0 +1,34 @@
+//
+// Created by zerjioang
+// https://github/zerjioang
+// Copyright (c) 2020. All rights reserved.
+//
+// SPDX-License-Identifier: GPL-3.0
+//
+
+//go:build go1.21
+
+package time32
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"log/slog"
+	"testing"
+)
+
+func TestLogValue(t *testing.T) {
+	t.Run("json-handler", func(t *testing.T) {
+		var buf bytes.Buffer
+		logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{
+			ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+				if a.Key == slog.TimeKey && len(groups) == 0 {
+					return slog.Attr{}
+				}
+				return a
+			},
+		}))
+		logger.Info("event", "when", Time32(1588228661))
+		assert.Equal(t, `{"level":"INFO","msg":"event","when":"2020-04-30T06:37:41Z"}`+"\n", buf.String())
+	})
+}
@@ -9,49 +9,617 @@
 package time32
 
 import (
+	"encoding/binary"
+	"errors"
+	"sync"
 	"sync/atomic"
 	"time"
+	"unsafe"
 )
 
-// lastTime stores the epoch value of last time reading
-var lastTime atomic.Value
-var lastUnix atomic.Value
-var lastUnixNano atomic.Value
+// cacheSnapshot holds every cached form of a single time reading, so that
+// all of them are always derived from the same tick.
+type cacheSnapshot struct {
+	time      time.Time
+	unix      int64
+	unixNano  int64
+	unixMilli int64
+	epoch     Time32
+	// mono is the runtime monotonic clock reading when the snapshot was taken
+	mono int64
+}
+
+// lastSnapshot points to the *cacheSnapshot of the last time reading.
+// Storing a pointer to an immutable snapshot, instead of boxing a time.Time
+// value into an atomic.Value, makes reads a plain pointer load followed by
+// a field access (see BenchmarkReuseTime): the only allocation happens once
+// per tick, never on the read path. Using an unsafe.Pointer rather than an
+// atomic.Value also keeps loadSnapshot cheap enough to be inlined.
+var lastSnapshot unsafe.Pointer
+
+// cachedSnapshot returns the last stored snapshot.
+func cachedSnapshot() *cacheSnapshot {
+	return (*cacheSnapshot)(atomic.LoadPointer(&lastSnapshot))
+}
+
+// defaultPrecision is the refresh rate of the cache started at init
+const defaultPrecision = 100 * time.Millisecond
+
+// cache ticker state. tickerMu serializes StartCache and StopCache, while
+// the other fields are accessed atomically so CacheInfo never blocks.
+var (
+	tickerMu       sync.Mutex
+	tickerStop     chan struct{}
+	cachePrecision int64
+	cacheTicks     uint64
+	cacheRunning   int32
+	// lastTickMono is the monotonic reading of the previous tick, or 0
+	// right after a (re)start; maxTickLag is the largest gap between two
+	// consecutive ticks, both in nanoseconds.
+	lastTickMono int64
+	maxTickLag   int64
+	// lastTickWall is the wall time, in Unix nanoseconds, read by the
+	// previous tick, and backwardJumps counts the ticks whose wall time was
+	// earlier than it. The cached snapshot is not used for the comparison
+	// since inline refreshes and ImportCacheState also write it.
+	lastTickWall  int64
+	backwardJumps uint64
+)
 
 func init() {
-	// store initial value
-	tt := time.Now()
-	lastTime.Store(tt)
-	lastUnix.Store(tt.Unix())
-	lastUnixNano.Store(tt.UnixNano())
-
-	// run each 0.1 seconds (aka precision)
-	ticker := time.NewTicker(100 * time.Millisecond)
-	go func() {
-		for {
-			select {
-			case t := <-ticker.C:
-				lastTime.Store(t)
-				lastUnix.Store(t.Unix())
-				lastUnixNano.Store(t.UnixNano())
-			}
+	// stores the initial value and runs each 0.1 seconds (aka precision)
+	StartCache(defaultPrecision)
+}
+
+// StartCache starts the goroutine that refreshes the cached time every
+// precision. If it is already running, it is restarted with the new
+// precision. The cache is started at init with a 0.1s precision.
+// It panics if precision <= 0.
+func StartCache(precision time.Duration) {
+	if precision <= 0 {
+		panic("time32: non-positive precision for StartCache")
+	}
+	tickerMu.Lock()
+	defer tickerMu.Unlock()
+	stopCache()
+	tickerStop = make(chan struct{})
+	atomic.StoreInt64(&cachePrecision, int64(precision))
+	atomic.StoreInt32(&cacheRunning, 1)
+	// refresh right away so a restart does not serve the value cached
+	// before the cache was stopped until the first tick arrives
+	storeSnapshot(time.Now())
+	atomic.StoreInt64(&lastTickMono, 0)
+	go runTicker(time.NewTicker(precision), tickerStop)
+}
+
+// StopCache stops the goroutine that refreshes the cached time. Until the
+// cache is started again, the Reuse* functions keep returning the last
+// cached value.
+func StopCache() {
+	tickerMu.Lock()
+	defer tickerMu.Unlock()
+	stopCache()
+}
+
+// stopCache stops the ticker goroutine, if any. tickerMu must be held.
+func stopCache() {
+	if tickerStop != nil {
+		close(tickerStop)
+		tickerStop = nil
+	}
+	atomic.StoreInt32(&cacheRunning, 0)
+}
+
+// runTicker updates the cache on every tick of ticker until stop is closed.
+func runTicker(ticker *time.Ticker, stop chan struct{}) {
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			// the value sent on ticker.C is the scheduled fire time, which
+			// is older than an inline refresh made by loadSnapshot while
+			// this goroutine was starved, so read the clock instead
+			tick(time.Now())
+		case <-stop:
+			return
+		}
+	}
+}
+
+// CachedClock is a time cache with its own refresh goroutine and precision,
+// independent from the package level cache behind the Reuse* functions, so
+// different subsystems can trade freshness for cost separately.
+// Like the Reuse* functions, it honors SetClock and SetClockOffset.
+type CachedClock struct {
+	unixNano int64
+	stop     chan struct{}
+	once     sync.Once
+}
+
+// NewCachedClock returns a CachedClock refreshed every precision. Call Stop
+// to release its goroutine once it is no longer needed.
+// It panics if precision <= 0.
+func NewCachedClock(precision Duration) *CachedClock {
+	if precision <= 0 {
+		panic("time32: non-positive precision for NewCachedClock")
+	}
+	c := &CachedClock{
+		unixNano: time.Now().UnixNano(),
+		stop:     make(chan struct{}),
+	}
+	go c.run(time.NewTicker(time.Duration(precision)))
+	return c
+}
+
+// run refreshes c on every tick of ticker until c is stopped.
+func (c *CachedClock) run(ticker *time.Ticker) {
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			// as in runTicker, the fire time lags under scheduler delay
+			atomic.StoreInt64(&c.unixNano, time.Now().UnixNano())
+		case <-c.stop:
+			return
+		}
+	}
+}
+
+// UnixNano returns the cached time as nanoseconds since the Unix epoch.
+func (c *CachedClock) UnixNano() int64 {
+	if clk := loadClock(); clk != nil {
+		return clk.Now().Add(time.Duration(ClockOffset())).UnixNano()
+	}
+	return atomic.LoadInt64(&c.unixNano) + int64(ClockOffset())
+}
+
+// Epoch returns the cached time as a Time32.
+func (c *CachedClock) Epoch() Time32 {
+	return Time32(c.UnixNano() / 1e9)
+}
+
+// Stop stops the refresh goroutine of c. The cached value stays frozen at
+// its last refresh. It is safe to call Stop more than once.
+func (c *CachedClock) Stop() {
+	c.once.Do(func() { close(c.stop) })
+}
+
+// CacheInfo returns the refresh precision of the cache, the number of ticks
+// processed since the package was initialized, and whether the refresh
+// goroutine is currently running.
+func CacheInfo() (precision time.Duration, ticks uint64, running bool) {
+	return time.Duration(atomic.LoadInt64(&cachePrecision)),
+		atomic.LoadUint64(&cacheTicks),
+		atomic.LoadInt32(&cacheRunning) == 1
+}
+
+// tick updates the cache with the reading t and notifies every subscriber.
+func tick(t time.Time) {
+	atomic.AddUint64(&cacheTicks, 1)
+	delta := recordTick(runtimeNano())
+	wall := t.UnixNano()
+	if prev := atomic.SwapInt64(&lastTickWall, wall); wall < prev {
+		atomic.AddUint64(&backwardJumps, 1)
+	}
+	storeSnapshot(t)
+	// subscribers get what Epoch returns, which honors SetClock and
+	// SetClockOffset
+	e := Epoch()
+	notifySubscribers(e, delta)
+	runScheduled(e)
+}
+
+// recordTick records a tick at the monotonic reading mono, updating the
+// maximum gap observed between consecutive ticks. It returns the gap since
+// the previous tick, or 0 for the first tick after the cache (re)started.
+func recordTick(mono int64) Duration {
+	prev := atomic.SwapInt64(&lastTickMono, mono)
+	if prev == 0 {
+		return 0
+	}
+	lag := mono - prev
+	for {
+		max := atomic.LoadInt64(&maxTickLag)
+		if lag <= max || atomic.CompareAndSwapInt64(&maxTickLag, max, lag) {
+			return Duration(lag)
 		}
-	}()
+	}
+}
+
+// MaxObservedLag returns the largest gap recorded between two consecutive
+// ticks of the cache since the package was initialized or since the last
+// ResetMaxObservedLag. A value well above the cache precision points to
+// GC pauses or scheduler starvation. Time spent stopped by StopCache is
+// not counted.
+func MaxObservedLag() Duration {
+	return Duration(atomic.LoadInt64(&maxTickLag))
+}
+
+// BackwardJumps returns how many cache ticks found the wall clock earlier
+// than the reading of the previous tick, as happens when NTP steps the clock
+// back or a VM is migrated. During such a tick the cached time moves
+// backward, so a non-zero count means callers may have seen it regress.
+func BackwardJumps() uint64 {
+	return atomic.LoadUint64(&backwardJumps)
+}
+
+// ResetMaxObservedLag clears the value returned by MaxObservedLag.
+func ResetMaxObservedLag() {
+	atomic.StoreInt64(&maxTickLag, 0)
+}
+
+// newSnapshot returns a snapshot of every cached form of t.
+func newSnapshot(t time.Time) *cacheSnapshot {
+	unix := t.Unix()
+	return &cacheSnapshot{
+		time:      t,
+		unix:      unix,
+		unixNano:  t.UnixNano(),
+		unixMilli: t.UnixMilli(),
+		epoch:     Time32(unix),
+		mono:      runtimeNano(),
+	}
+}
+
+// storeSnapshot replaces the cached time reading with t.
+func storeSnapshot(t time.Time) {
+	atomic.StorePointer(&lastSnapshot, unsafe.Pointer(newSnapshot(t)))
+}
+
+// slowPath is 1 while an installed Clock, a clock offset or a maximum
+// staleness requires loadSnapshot to do more than return the cached
+// snapshot. It keeps the default Reuse* path down to two atomic loads.
+var (
+	slowPathMu sync.Mutex
+	slowPath   int32
+)
+
+// updateSlowPath applies the setting change made by set and recomputes
+// slowPath. Changes are serialized so concurrent setters cannot leave a
+// stale flag behind.
+func updateSlowPath(set func()) {
+	slowPathMu.Lock()
+	defer slowPathMu.Unlock()
+	set()
+	var v int32
+	if loadClock() != nil || ClockOffset() != 0 || atomic.LoadInt64(&maxStaleness) > 0 {
+		v = 1
+	}
+	atomic.StoreInt32(&slowPath, v)
+}
+
+// loadSnapshot returns the last cached time reading, or a fresh reading
+// of the installed Clock if there is one, shifted by the clock offset.
+func loadSnapshot() *cacheSnapshot {
+	if atomic.LoadInt32(&slowPath) == 0 {
+		return cachedSnapshot()
+	}
+	return loadSnapshotSlow()
+}
+
+// loadSnapshotSlow is loadSnapshot when an installed Clock, a clock offset
+// or a maximum staleness is set.
+func loadSnapshotSlow() *cacheSnapshot {
+	if c := loadClock(); c != nil {
+		return newSnapshot(c.Now().Add(time.Duration(ClockOffset())))
+	}
+	s := cachedSnapshot()
+	if max := atomic.LoadInt64(&maxStaleness); max > 0 && runtimeNano()-s.mono > max {
+		// the ticker is late or stopped: refresh the cache inline
+		s = newSnapshot(time.Now())
+		atomic.StorePointer(&lastSnapshot, unsafe.Pointer(s))
+	}
+	if offset := ClockOffset(); offset != 0 {
+		shifted := newSnapshot(s.time.Add(time.Duration(offset)))
+		// keep the monotonic reading of the cached value, see ReuseNow
+		shifted.mono = s.mono
+		return shifted
+	}
+	return s
+}
+
+// cacheStateVersion is the format version written by ExportCacheState
+const cacheStateVersion byte = 1
+
+// ErrInvalidCacheState is returned by ImportCacheState for malformed input.
+var ErrInvalidCacheState = errors.New("time32: invalid cache state")
+
+// ExportCacheState serializes the last cached time reading, so that a
+// forked or restored process can seed its cache with ImportCacheState.
+func ExportCacheState() []byte {
+	b := make([]byte, 9)
+	b[0] = cacheStateVersion
+	binary.BigEndian.PutUint64(b[1:], uint64(cachedSnapshot().unixNano))
+	return b
+}
+
+// ImportCacheState replaces the cached time reading with one serialized by
+// ExportCacheState. The imported reading is served by the Reuse* functions
+// only until the next tick, which immediately supersedes it.
+func ImportCacheState(b []byte) error {
+	if len(b) != 9 || b[0] != cacheStateVersion {
+		return ErrInvalidCacheState
+	}
+	storeSnapshot(time.Unix(0, int64(binary.BigEndian.Uint64(b[1:]))))
+	return nil
+}
+
+// maxStaleness is the maximum age, in nanoseconds, of a cached reading
+// before the Reuse* functions refresh it inline. Zero disables the check.
+var maxStaleness int64
+
+// SetMaxStaleness bounds the age of the values returned by the Reuse*
+// functions: when a call finds that the cached reading is older than d,
+// it reads the system clock and refreshes the cache inline, even if the
+// ticker goroutine is starved or stopped. A d <= 0 disables the bound,
+// which is the default.
+func SetMaxStaleness(d Duration) {
+	if d < 0 {
+		d = 0
+	}
+	updateSlowPath(func() {
+		atomic.StoreInt64(&maxStaleness, int64(d))
+	})
+}
+
+// CacheAge returns the time elapsed since the cached reading was taken.
+func CacheAge() Duration {
+	return Duration(runtimeNano() - cachedSnapshot().mono)
 }
 
 // ReuseTime is a function that reuses last readed epoch value
 // this function is meant to be used on high demanding applications that require
 // time value readings with high frequency. Instead of making a syscall on every request,
 // last time value is cached. Cache duration has a window of 0.1s so all calls requested during
-// that period will reuse the same epoch time value
+// that period will reuse the same epoch time value.
+// When a Clock is installed with SetClock, its current value is returned instead.
 func ReuseTime() time.Time {
-	return lastTime.Load().(time.Time)
+	return loadSnapshot().time
+}
+
+// ReuseNow is like ReuseTime, but it returns this package's Time. The
+// result carries the monotonic clock reading taken when the cached value
+// was refreshed, so Sub and Since between a ReuseNow result and a fresh
+// Now stay monotonic-correct even if the wall clock is stepped.
+// When a Clock is installed with SetClock, the result has no monotonic
+// clock reading.
+func ReuseNow() Time {
+	s := loadSnapshot()
+	nsec := int32(s.unixNano - s.unix*1e9)
+	if loadClock() != nil {
+		return unixTime(s.unix, nsec)
+	}
+	// the wall time of s already includes the clock offset, shift the
+	// monotonic reading too, as Now does
+	return nowTime(s.unix, nsec, s.mono+int64(ClockOffset()))
 }
 
 func ReuseUnix() int64 {
-	return lastUnix.Load().(int64)
+	return loadSnapshot().unix
 }
 
 func ReuseUnixNano() int64 {
-	return lastUnixNano.Load().(int64)
-}
\ No newline at end of file
+	return loadSnapshot().unixNano
+}
+
+// Unit selects the resolution returned by ReuseEpoch.
+type Unit int32
+
+const (
+	// UnitSeconds makes ReuseEpoch return seconds since the Unix epoch.
+	UnitSeconds Unit = iota
+	// UnitMillis makes ReuseEpoch return milliseconds since the Unix epoch.
+	UnitMillis
+)
+
+// reuseUnit is the Unit currently used by ReuseEpoch
+var reuseUnit int32
+
+// SetReuseUnit sets the unit of the values returned by ReuseEpoch. The
+// cache keeps every unit for each tick, so switching takes effect on the
+// next call. Milliseconds do not fit a uint32 (they wrap after ~49 days),
+// which is why ReuseEpoch returns an int64 rather than a Time32.
+// It panics if u is not a known Unit.
+func SetReuseUnit(u Unit) {
+	if u != UnitSeconds && u != UnitMillis {
+		panic("time32: unknown reuse unit")
+	}
+	atomic.StoreInt32(&reuseUnit, int32(u))
+}
+
+// ReuseEpoch returns the cached time since the Unix epoch, in seconds or
+// milliseconds as configured with SetReuseUnit. Seconds are the default.
+func ReuseEpoch() int64 {
+	s := loadSnapshot()
+	if Unit(atomic.LoadInt32(&reuseUnit)) == UnitMillis {
+		return s.unixMilli
+	}
+	return s.unix
+}
+
+// ReuseSnapshot returns every cached form of the last time reading at once.
+// All values come from the same tick, which is not guaranteed when calling
+// ReuseTime, ReuseUnix and ReuseUnixNano one after another.
+func ReuseSnapshot() (t time.Time, unix int64, unixNano int64, epoch Time32) {
+	s := loadSnapshot()
+	return s.time, s.unix, s.unixNano, s.epoch
+}
+
+// subscribers and deltaSubscribers store the channels registered with
+// Subscribe and SubscribeDelta
+var (
+	subscribersMu    sync.Mutex
+	subscribers      = map[chan Time32]struct{}{}
+	deltaSubscribers = map[chan Duration]struct{}{}
+)
+
+// Subscribe returns a channel that receives the epoch value of every cache
+// tick, and a function that unsubscribes and closes the channel.
+// Sends never block the ticker: if the subscriber has not consumed the
+// previous value yet, the new one is dropped.
+func Subscribe() (<-chan Time32, func()) {
+	ch := make(chan Time32, 1)
+	subscribersMu.Lock()
+	subscribers[ch] = struct{}{}
+	subscribersMu.Unlock()
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			subscribersMu.Lock()
+			delete(subscribers, ch)
+			close(ch)
+			subscribersMu.Unlock()
+		})
+	}
+}
+
+// SubscribeDelta returns a channel that receives, on every cache tick, the
+// monotonic time elapsed since the previous tick, and a function that
+// unsubscribes and closes the channel. Nothing is sent for the first tick
+// after the cache is (re)started. As with Subscribe, sends never block the
+// ticker and are dropped if the previous value was not consumed yet.
+func SubscribeDelta() (<-chan Duration, func()) {
+	ch := make(chan Duration, 1)
+	subscribersMu.Lock()
+	deltaSubscribers[ch] = struct{}{}
+	subscribersMu.Unlock()
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			subscribersMu.Lock()
+			delete(deltaSubscribers, ch)
+			close(ch)
+			subscribersMu.Unlock()
+		})
+	}
+}
+
+// notifySubscribers sends epoch to every Subscribe subscriber, and delta,
+// unless it is 0, to every SubscribeDelta subscriber, without blocking.
+func notifySubscribers(epoch Time32, delta Duration) {
+	subscribersMu.Lock()
+	for ch := range subscribers {
+		select {
+		case ch <- epoch:
+		default:
+		}
+	}
+	if delta != 0 {
+		for ch := range deltaSubscribers {
+			select {
+			case ch <- delta:
+			default:
+			}
+		}
+	}
+	subscribersMu.Unlock()
+}
+
+// scheduledTask is a callback registered with DoAt
+type scheduledTask struct {
+	target Time32
+	fn     func()
+}
+
+// scheduled stores the tasks registered with DoAt that did not run yet
+var (
+	scheduledMu sync.Mutex
+	scheduled   []scheduledTask
+)
+
+// DoAt registers fn to be called once, by the cache ticker goroutine, on
+// the first tick where Epoch() >= target. Every registration fires
+// independently. The resolution is bounded by both the one second
+// granularity of Time32 and the cache precision, and nothing fires while
+// the cache is stopped. fn runs on the ticker goroutine, so it must return
+// quickly or hand long work off to another goroutine.
+func DoAt(target Time32, fn func()) {
+	scheduledMu.Lock()
+	scheduled = append(scheduled, scheduledTask{target: target, fn: fn})
+	scheduledMu.Unlock()
+}
+
+// runScheduled calls and unregisters every task due at now.
+func runScheduled(now Time32) {
+	scheduledMu.Lock()
+	var due []func()
+	pending := scheduled[:0]
+	for _, task := range scheduled {
+		if now >= task.target {
+			due = append(due, task.fn)
+		} else {
+			pending = append(pending, task)
+		}
+	}
+	for i := len(pending); i < len(scheduled); i++ {
+		scheduled[i] = scheduledTask{}
+	}
+	scheduled = pending
+	scheduledMu.Unlock()
+	for _, fn := range due {
+		fn()
+	}
+}
+
+// CurrentYear returns the current UTC year computed from the cached epoch
+// value, without building a time.Time or touching any *Location data.
+func CurrentYear() int {
+	year, _, _, _ := absDate(reuseAbs(), false)
+	return year
+}
+
+// CurrentMonth returns the current UTC month computed from the cached epoch value.
+func CurrentMonth() time.Month {
+	_, month, _, _ := absDate(reuseAbs(), true)
+	return time.Month(month)
+}
+
+// CurrentDay returns the current UTC day of the month computed from the cached epoch value.
+func CurrentDay() int {
+	_, _, day, _ := absDate(reuseAbs(), true)
+	return day
+}
+
+// CurrentHourBucket returns the start of the current UTC hour computed from
+// the cached epoch value, a stable key for hourly sharding.
+func CurrentHourBucket() Time32 {
+	return HourBucketOf(loadSnapshot().epoch)
+}
+
+// HourBucketOf returns the start of the UTC hour containing t, the bucket
+// CurrentHourBucket returns while t is the current time.
+func HourBucketOf(t Time32) Time32 {
+	return t.TruncateHour()
+}
+
+// UntilNext returns the time left, measured from the cached epoch value,
+// until the next wall clock boundary that is a multiple of d since the Unix
+// epoch (for example the top of the next minute when d is Minute), so
+// callers can sleep to align their work. It panics if d <= 0.
+func UntilNext(d Duration) Duration {
+	if d <= 0 {
+		panic("time32: non-positive interval for UntilNext")
+	}
+	return d - Duration(ReuseUnixNano()%int64(d))
+}
+
+// UntilDailyTime returns the time left, measured from the cached epoch
+// value, until the next occurrence of hour:min UTC. As with UntilNext, if
+// the cached time is exactly hour:min the next occurrence is tomorrow's.
+// It panics unless 0 <= hour < 24 and 0 <= min < 60.
+func UntilDailyTime(hour, min int) Duration {
+	if hour < 0 || hour > 23 || min < 0 || min > 59 {
+		panic("time32: invalid time of day for UntilDailyTime")
+	}
+	target := Duration(hour)*Hour + Duration(min)*Minute
+	left := target - Duration(ReuseUnixNano()%int64(24*Hour))
+	if left <= 0 {
+		left += 24 * Hour
+	}
+	return left
+}
+
+// reuseAbs returns the cached epoch value as an absolute time.
+func reuseAbs() uint64 {
+	return uint64(ReuseUnix() + (unixToInternal + internalToAbsolute))
+}
@@ -1,28 +1,413 @@
 package time32
 
 import (
-	"fmt"
 	"github.com/stretchr/testify/assert"
-	"math"
+	"sync/atomic"
 	"testing"
+	"time"
+	"unsafe"
 )
 
 func TestTicker(t *testing.T) {
 	t.Run("reuse-time", func(t *testing.T) {
 		tt := Now()
 		reusedTt := ReuseTime()
-		assert.Equal(t, tt.Unix(), reusedTt.Unix())
+		// the cache may still hold the previous second
+		assert.InDelta(t, tt.Unix(), reusedTt.Unix(), 1)
 	})
 	t.Run("reuse-unix", func(t *testing.T) {
 		tt := Now()
 		reusedTt := ReuseUnix()
-		assert.Equal(t, tt.Unix(), reusedTt)
+		assert.InDelta(t, tt.Unix(), reusedTt, 1)
 	})
 	t.Run("reuse-nanos", func(t *testing.T) {
 		tt := Now()
 		reusedTt := ReuseUnixNano()
-		diff := math.Abs(float64(tt.UnixNano()-reusedTt))
-		fmt.Println(diff)
-		assert.True(t, diff < 0.2*1000*1000)
+		// the cache is at most one refresh behind, plus the time the
+		// ticker goroutine takes to wake up
+		diff := tt.UnixNano() - reusedTt
+		assert.True(t, diff >= 0)
+		assert.True(t, diff < int64(2*defaultPrecision))
 	})
-}
\ No newline at end of file
+	t.Run("reuse-now", func(t *testing.T) {
+		for i := 0; i < 5; i++ {
+			reused := ReuseNow()
+			d := Now().Sub(reused)
+			assert.True(t, d >= 0)
+			assert.True(t, d < 200*Millisecond)
+			assert.InDelta(t, ReuseUnix(), reused.Unix(), 1)
+			time.Sleep(30 * time.Millisecond)
+		}
+		func() {
+			// with an offset, the result still carries the monotonic
+			// reading of the cached value, not one taken by ReuseNow
+			SetClockOffset(Hour)
+			defer SetClockOffset(0)
+			StopCache()
+			defer StartCache(defaultPrecision)
+			time.Sleep(50 * time.Millisecond)
+			d := Now().Sub(ReuseNow())
+			assert.True(t, d >= 50*Millisecond)
+			assert.True(t, d < Second)
+		}()
+		SetClock(NewManualClock(time.Unix(1588228661, 5)))
+		defer SetClock(nil)
+		assert.Equal(t, int64(1588228661), ReuseNow().Unix())
+		assert.Equal(t, 5, ReuseNow().Nanosecond())
+	})
+	t.Run("reuse-time-no-allocs", func(t *testing.T) {
+		allocs := testing.AllocsPerRun(100, func() {
+			_ = ReuseTime()
+		})
+		assert.Equal(t, 0.0, allocs)
+	})
+	t.Run("current-date", func(t *testing.T) {
+		now := time.Now().UTC()
+		assert.Equal(t, now.Year(), CurrentYear())
+		assert.Equal(t, now.Month(), CurrentMonth())
+		assert.Equal(t, now.Day(), CurrentDay())
+	})
+	t.Run("hour-bucket", func(t *testing.T) {
+		clock := NewManualClock(time.Unix(1588228661, 0))
+		SetClock(clock)
+		defer SetClock(nil)
+		first := CurrentHourBucket()
+		assert.Equal(t, "2020-04-30T06:00:00Z", first.String())
+		clock.Advance(20 * time.Minute)
+		assert.Equal(t, first, CurrentHourBucket())
+		clock.Advance(time.Hour)
+		assert.Equal(t, first+3600, CurrentHourBucket())
+		assert.Equal(t, first, HourBucketOf(1588228661))
+		assert.Equal(t, first, HourBucketOf(first))
+	})
+	t.Run("reuse-snapshot", func(t *testing.T) {
+		for i := 0; i < 5; i++ {
+			tt, unix, unixNano, epoch := ReuseSnapshot()
+			assert.Equal(t, tt.Unix(), unix)
+			assert.Equal(t, tt.UnixNano(), unixNano)
+			assert.Equal(t, Time32(unix), epoch)
+			time.Sleep(50 * time.Millisecond)
+		}
+	})
+	t.Run("cache-info", func(t *testing.T) {
+		precision, before, running := CacheInfo()
+		assert.Equal(t, 100*time.Millisecond, precision)
+		assert.True(t, running)
+		time.Sleep(5 * precision)
+		_, after, running := CacheInfo()
+		assert.True(t, running)
+		assert.InDelta(t, 5, after-before, 2)
+	})
+	t.Run("stop-and-start", func(t *testing.T) {
+		StopCache()
+		_, before, running := CacheInfo()
+		assert.False(t, running)
+		time.Sleep(250 * time.Millisecond)
+		_, after, _ := CacheInfo()
+		assert.Equal(t, before, after)
+
+		StartCache(10 * time.Millisecond)
+		precision, _, running := CacheInfo()
+		assert.True(t, running)
+		assert.Equal(t, 10*time.Millisecond, precision)
+		StartCache(defaultPrecision)
+		assert.InDelta(t, time.Now().Unix(), ReuseUnix(), 1)
+	})
+	t.Run("max-observed-lag", func(t *testing.T) {
+		time.Sleep(3 * defaultPrecision)
+		lag := MaxObservedLag()
+		assert.True(t, lag >= 50*Millisecond)
+
+		StopCache()
+		defer StartCache(defaultPrecision)
+		ResetMaxObservedLag()
+		assert.Equal(t, Duration(0), MaxObservedLag())
+		// simulate a tick delayed by a 2s pause
+		mono := runtimeNano()
+		recordTick(mono)
+		recordTick(mono + int64(2*time.Second))
+		assert.Equal(t, 2*Second, MaxObservedLag())
+		recordTick(mono + int64(2*time.Second+defaultPrecision))
+		assert.Equal(t, 2*Second, MaxObservedLag())
+
+		ResetMaxObservedLag()
+		assert.Equal(t, Duration(0), MaxObservedLag())
+	})
+	t.Run("reuse-epoch-unit", func(t *testing.T) {
+		defer SetReuseUnit(UnitSeconds)
+		secs := ReuseEpoch()
+		assert.InDelta(t, time.Now().Unix(), secs, 1)
+		SetReuseUnit(UnitMillis)
+		millis := ReuseEpoch()
+		assert.InDelta(t, time.Now().UnixNano()/int64(time.Millisecond), millis, 1000)
+		assert.InDelta(t, secs*1000, millis, 2000)
+		SetReuseUnit(UnitSeconds)
+		assert.InDelta(t, secs, ReuseEpoch(), 1)
+		assert.Panics(t, func() { SetReuseUnit(Unit(7)) })
+	})
+	t.Run("backward-jumps", func(t *testing.T) {
+		StopCache()
+		defer StartCache(defaultPrecision)
+		clock := NewManualClock(time.Unix(1588228661, 0))
+		tick(clock.Now())
+		jumps := BackwardJumps()
+		clock.Advance(time.Second)
+		tick(clock.Now())
+		assert.Equal(t, jumps, BackwardJumps())
+		clock.Advance(-5 * time.Second)
+		tick(clock.Now())
+		assert.Equal(t, jumps+1, BackwardJumps())
+		tick(clock.Now())
+		assert.Equal(t, jumps+1, BackwardJumps())
+
+		// a delayed tick behind an inline refresh or an imported state is
+		// not a clock regression
+		storeSnapshot(clock.Now().Add(time.Second))
+		clock.Advance(500 * time.Millisecond)
+		tick(clock.Now())
+		assert.Equal(t, jumps+1, BackwardJumps())
+		storeSnapshot(clock.Now().Add(time.Hour))
+		assert.NoError(t, ImportCacheState(ExportCacheState()))
+		clock.Advance(time.Second)
+		tick(clock.Now())
+		assert.Equal(t, jumps+1, BackwardJumps())
+	})
+	t.Run("cache-age", func(t *testing.T) {
+		age := CacheAge()
+		assert.True(t, age >= 0)
+		assert.True(t, age < 200*Millisecond)
+	})
+	t.Run("max-staleness", func(t *testing.T) {
+		StopCache()
+		defer StartCache(defaultPrecision)
+		SetMaxStaleness(10 * Millisecond)
+		defer SetMaxStaleness(0)
+
+		time.Sleep(200 * time.Millisecond)
+		assert.True(t, CacheAge() >= 200*Millisecond)
+		reused := ReuseUnixNano()
+		diff := time.Now().UnixNano() - reused
+		assert.True(t, diff >= 0 && diff < int64(10*time.Millisecond))
+		assert.True(t, CacheAge() < 10*Millisecond)
+	})
+	t.Run("cache-state-round-trip", func(t *testing.T) {
+		StopCache()
+		defer StartCache(defaultPrecision)
+
+		state := ExportCacheState()
+		exported := ReuseUnixNano()
+		storeSnapshot(time.Unix(0, 0))
+		assert.Equal(t, int64(0), ReuseUnixNano())
+
+		assert.NoError(t, ImportCacheState(state))
+		assert.Equal(t, exported, ReuseUnixNano())
+		assert.Equal(t, exported/1e9, ReuseUnix())
+		assert.Equal(t, ErrInvalidCacheState, ImportCacheState(state[:5]))
+		assert.Equal(t, ErrInvalidCacheState, ImportCacheState(append([]byte{9}, state[1:]...)))
+	})
+	t.Run("do-at", func(t *testing.T) {
+		clock := NewManualClock(time.Unix(1588228661, 0))
+		SetClock(clock)
+		defer SetClock(nil)
+		target := Time32(1588228661 + 2)
+		fired := make(chan Time32, 2)
+		DoAt(target, func() { fired <- Epoch() })
+		DoAt(target, func() { fired <- Epoch() })
+
+		// several ticks go by before the target is reached
+		clock.Advance(time.Second)
+		select {
+		case <-fired:
+			t.Fatal("callback fired before its target")
+		case <-time.After(3 * defaultPrecision):
+		}
+
+		clock.Advance(time.Second)
+		for i := 0; i < 2; i++ {
+			select {
+			case at := <-fired:
+				assert.Equal(t, target, at)
+			case <-time.After(time.Second):
+				t.Fatal("callback did not fire")
+			}
+		}
+	})
+	t.Run("until-next", func(t *testing.T) {
+		clock := NewManualClock(time.Unix(100, 0))
+		SetClock(clock)
+		defer SetClock(nil)
+
+		assert.Equal(t, 20*Second, UntilNext(Minute))
+		assert.Equal(t, Hour-100*Second, UntilNext(Hour))
+		clock.Set(time.Unix(120, 0))
+		assert.Equal(t, Minute, UntilNext(Minute))
+		assert.Panics(t, func() { UntilNext(0) })
+	})
+	t.Run("until-daily-time", func(t *testing.T) {
+		// 2020-04-30T06:37:41Z
+		clock := NewManualClock(time.Unix(1588228661, 0))
+		SetClock(clock)
+		defer SetClock(nil)
+
+		assert.Equal(t, 22*Minute+19*Second, UntilDailyTime(7, 0))
+		assert.Equal(t, 17*Hour+22*Minute+19*Second, UntilDailyTime(0, 0))
+		assert.Equal(t, 24*Hour-41*Second, UntilDailyTime(6, 37))
+		clock.Set(time.Unix(1588228620, 0))
+		assert.Equal(t, 24*Hour, UntilDailyTime(6, 37))
+		assert.Panics(t, func() { UntilDailyTime(24, 0) })
+		assert.Panics(t, func() { UntilDailyTime(0, 60) })
+		assert.Panics(t, func() { UntilDailyTime(-1, 0) })
+	})
+	t.Run("subscribe", func(t *testing.T) {
+		ch, unsubscribe := Subscribe()
+		for i := 0; i < 3; i++ {
+			select {
+			case epoch := <-ch:
+				assert.InDelta(t, time.Now().Unix(), int64(epoch), 1)
+			case <-time.After(time.Second):
+				t.Fatal("no tick received")
+			}
+		}
+		unsubscribe()
+		unsubscribe()
+		// drain a value sent before unsubscribing, if any
+		for range ch {
+		}
+		_, open := <-ch
+		assert.False(t, open)
+	})
+	t.Run("subscribe-manual-clock", func(t *testing.T) {
+		SetClock(NewManualClock(time.Unix(1588228661, 0)))
+		defer SetClock(nil)
+		SetClockOffset(Hour)
+		defer SetClockOffset(0)
+		ch, unsubscribe := Subscribe()
+		defer unsubscribe()
+		select {
+		case epoch := <-ch:
+			assert.Equal(t, Epoch(), epoch)
+		case <-time.After(time.Second):
+			t.Fatal("no tick received")
+		}
+	})
+	t.Run("subscribe-delta", func(t *testing.T) {
+		precision, _, _ := CacheInfo()
+		ch, unsubscribe := SubscribeDelta()
+		for i := 0; i < 3; i++ {
+			select {
+			case delta := <-ch:
+				assert.InDelta(t, int64(precision), int64(delta), float64(precision/2))
+			case <-time.After(time.Second):
+				t.Fatal("no tick received")
+			}
+		}
+		unsubscribe()
+		unsubscribe()
+		for range ch {
+		}
+		_, open := <-ch
+		assert.False(t, open)
+	})
+}
+
+func TestCachedClock(t *testing.T) {
+	t.Run("independent-precisions", func(t *testing.T) {
+		fine := NewCachedClock(5 * Millisecond)
+		defer fine.Stop()
+		coarse := NewCachedClock(200 * Millisecond)
+		defer coarse.Stop()
+
+		fineValues := map[int64]bool{}
+		coarseValues := map[int64]bool{}
+		for i := 0; i < 100; i++ {
+			fineValues[fine.UnixNano()] = true
+			coarseValues[coarse.UnixNano()] = true
+			time.Sleep(3 * time.Millisecond)
+		}
+		assert.True(t, len(fineValues) > len(coarseValues))
+		assert.True(t, len(coarseValues) <= 4)
+		assert.InDelta(t, time.Now().Unix(), int64(fine.Epoch()), 1)
+		assert.InDelta(t, time.Now().Unix(), int64(coarse.Epoch()), 1)
+	})
+	t.Run("stop", func(t *testing.T) {
+		c := NewCachedClock(Millisecond)
+		c.Stop()
+		c.Stop()
+		time.Sleep(5 * time.Millisecond)
+		frozen := c.UnixNano()
+		time.Sleep(10 * time.Millisecond)
+		assert.Equal(t, frozen, c.UnixNano())
+		assert.Panics(t, func() { NewCachedClock(0) })
+	})
+	t.Run("manual-clock", func(t *testing.T) {
+		c := NewCachedClock(Second)
+		defer c.Stop()
+		SetClock(NewManualClock(time.Unix(1588228661, 0)))
+		defer SetClock(nil)
+		assert.Equal(t, Time32(1588228661), c.Epoch())
+	})
+}
+
+func BenchmarkReuseTime(b *testing.B) {
+	b.Run("reuse-time", func(b *testing.B) {
+		b.ReportAllocs()
+		b.SetBytes(1)
+		b.ResetTimer()
+		var ep time.Time
+		for i := 0; i < b.N; i++ {
+			ep = ReuseTime()
+		}
+		if ep.Unix() == 0 {
+			b.Log("time is zero")
+		}
+	})
+	b.Run("load-value-box", func(b *testing.B) {
+		// previous approach: time.Time boxed into the atomic.Value
+		var v atomic.Value
+		v.Store(time.Now())
+		b.ReportAllocs()
+		b.SetBytes(1)
+		b.ResetTimer()
+		var ep time.Time
+		for i := 0; i < b.N; i++ {
+			ep = v.Load().(time.Time)
+		}
+		if ep.Unix() == 0 {
+			b.Log("time is zero")
+		}
+	})
+	b.Run("load-pointer", func(b *testing.B) {
+		// current approach: pointer to an immutable snapshot
+		cachePtr := unsafe.Pointer(newSnapshot(time.Now()))
+		b.ReportAllocs()
+		b.SetBytes(1)
+		b.ResetTimer()
+		var ep time.Time
+		for i := 0; i < b.N; i++ {
+			ep = (*cacheSnapshot)(atomic.LoadPointer(&cachePtr)).time
+		}
+		if ep.Unix() == 0 {
+			b.Log("time is zero")
+		}
+	})
+	b.Run("store-value-box", func(b *testing.B) {
+		var v atomic.Value
+		now := time.Now()
+		b.ReportAllocs()
+		b.SetBytes(1)
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			v.Store(now)
+		}
+	})
+	b.Run("store-pointer", func(b *testing.B) {
+		var cachePtr unsafe.Pointer
+		now := time.Now()
+		b.ReportAllocs()
+		b.SetBytes(1)
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			atomic.StorePointer(&cachePtr, unsafe.Pointer(newSnapshot(now)))
+		}
+	})
+}
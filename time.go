@@ -76,7 +76,7 @@
 package time32
 
 import (
-	_ "unsafe" // for go:linkname
+	"time"
 )
 
 // A Time represents an instant in time with nanosecond precision.
@@ -780,6 +780,51 @@ func (d Duration) Round(m Duration) Duration {
 	return maxDuration // overflow
 }
 
+// Abs returns the absolute value of d.
+// As a special case, minDuration is converted to maxDuration.
+func (d Duration) Abs() Duration {
+	switch {
+	case d >= 0:
+		return d
+	case d == minDuration:
+		return maxDuration
+	default:
+		return -d
+	}
+}
+
+// Clamp returns d bounded to the range [min, max].
+// It panics if min > max.
+func (d Duration) Clamp(min, max Duration) Duration {
+	if min > max {
+		panic("time32: Clamp called with min > max")
+	}
+	switch {
+	case d < min:
+		return min
+	case d > max:
+		return max
+	default:
+		return d
+	}
+}
+
+// Normalize returns t in canonical form. The packed nanosecond field can
+// hold values up to 2^30-1; any part of it that is a whole second or more
+// is carried into the seconds, keeping the monotonic clock reading. Every
+// constructor in this package returns canonical times, normalizing the
+// readings it is given, so this is only needed for values built by other
+// means.
+func (t Time) Normalize() Time {
+	nsec := t.wall & nsecMask
+	if nsec < 1e9 {
+		return t
+	}
+	t.wall = t.wall&^nsecMask | nsec%1e9
+	t.addSec(int64(nsec / 1e9))
+	return t
+}
+
 // Add returns the time t+d.
 func (t Time) Add(d Duration) Time {
 	dsec := int64(d / 1e9)
@@ -834,6 +879,21 @@ func (t Time) Sub(u Time) Duration {
 	}
 }
 
+// WallJumpSince returns how far the wall clock jumped between u and t,
+// computed as the wall clock delta t-u minus the monotonic clock delta t-u.
+// A positive result means the wall clock was stepped forward, a negative
+// one that it was stepped backward.
+// Both t and u must carry a monotonic clock reading (as returned by Now);
+// otherwise no jump can be detected and WallJumpSince returns 0.
+func (t Time) WallJumpSince(u Time) Duration {
+	if t.wall&u.wall&hasMonotonic == 0 {
+		return 0
+	}
+	wall := Duration(t.sec()-u.sec())*Second + Duration(t.nsec()-u.nsec())
+	mono := Duration(t.ext - u.ext)
+	return wall - mono
+}
+
 // Since returns the time elapsed since t.
 // It is shorthand for time.Now().Sub(t).
 func Since(t Time) Duration {
@@ -1018,31 +1078,56 @@ func daysSinceEpoch(year int) uint64 {
 	return d
 }
 
-//go:noescape
-//go:linkname nanotime runtime.nanotime
-func nanotime() int64
-
-//go:noescape
-//go:linkname time_now time.now
-func time_now() (sec int64, nsec int32, mono int64)
-
-// runtimeNano returns the current value of the runtime clock in nanoseconds.
-//go:linkname runtimeNano runtime.nanotime
-func runtimeNano() int64
-
 // Monotonic times are reported as offsets from startNano.
 // We initialize startNano to runtimeNano() - 1 so that on systems where
 // monotonic time resolution is fairly low (e.g. Windows 2008
 // which appears to have a default resolution of 15ms),
 // we avoid ever reporting a monotonic time of 0.
 // (Callers may want to use 0 as "time not set".)
+//
+// runtimeNano is a function declaration, not a function variable, so it
+// can never be observed as nil here, and package-level variables are
+// initialized in dependency order regardless of file order, before any
+// init function runs. startNano is therefore set before any code in this
+// package, including the cache goroutine started from init, can read it.
 var startNano int64 = runtimeNano() - 1
 
+// UsingLinkname reports whether the package reads the runtime clocks
+// directly through go:linkname (the default) or through the time.Now
+// based fallback selected with the time32_nolinkname build tag.
+func UsingLinkname() bool {
+	return usingLinkname
+}
+
+// Uptime returns the time elapsed since this package was initialized,
+// measured with the monotonic clock so it is not affected by wall clock
+// changes. Package initialization happens very early, but it is not the
+// exact process start time.
+func Uptime() Duration {
+	return Duration(runtimeNano() - startNano)
+}
+
 // Now returns the current local time.
 func Now() Time {
-	sec, nsec, mono := time_now()
+	if c := loadClock(); c != nil {
+		t := c.Now().Add(time.Duration(ClockOffset()))
+		return Unix(t.Unix(), int64(t.Nanosecond()))
+	}
+	t := nowTime(wallNow())
+	if offset := ClockOffset(); offset != 0 {
+		return t.Add(offset)
+	}
+	return t
+}
+
+// nowTime builds a Time from a wallNow reading, keeping the monotonic
+// clock reading whenever the wall time fits in the packed representation.
+func nowTime(sec int64, nsec int32, mono int64) Time {
 	mono -= startNano
 	sec += unixToInternal - minWall
+	if uint32(nsec) >= 1e9 {
+		sec, nsec = normNsec(sec, nsec)
+	}
 	if uint64(sec)>>33 != 0 {
 		return Time{uint64(nsec), sec + minWall}
 	}
@@ -1050,9 +1135,25 @@ func Now() Time {
 }
 
 func unixTime(sec int64, nsec int32) Time {
+	if uint32(nsec) >= 1e9 {
+		sec, nsec = normNsec(sec, nsec)
+	}
 	return Time{uint64(nsec), sec + unixToInternal}
 }
 
+// normNsec carries the whole seconds of nsec into sec, so that the returned
+// nanoseconds are in the range [0, 999999999]. It defends the constructors
+// against readings they cannot pack.
+func normNsec(sec int64, nsec int32) (int64, int32) {
+	sec += int64(nsec / 1e9)
+	nsec %= 1e9
+	if nsec < 0 {
+		sec--
+		nsec += 1e9
+	}
+	return sec, nsec
+}
+
 // Unix returns t as a Unix time, the number of seconds elapsed
 // since January 1, 1970 UTC. The result does not depend on the
 // location associated with t.
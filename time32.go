@@ -10,6 +10,13 @@
 // without the need of using internal pointers for UTC location data ( *loc )
 package time32
 
+import (
+	"context"
+	"math"
+	"sync/atomic"
+	"time"
+)
+
 /*
 Time32 Defines our own time unit which will always hold epoch time
 in millis. Example: 1588228661
@@ -21,39 +28,707 @@ x2 size reduction changing data size
 */
 type Time32 uint32
 
+// AddDate returns t plus the given number of days of 86400 seconds.
+//
+// Deprecated: the name suggests calendar arithmetic like Time.AddDate, but
+// only days are supported. Use AddCalendarDays instead, or Time.AddDate for
+// year and month arithmetic.
 func (t Time32) AddDate(days int) Time32 {
-	v := int(t) + (days * 86400)
-	return Time32(v)
+	return t.AddCalendarDays(days)
+}
+
+// AddCalendarDays returns t plus n calendar days. Since Time32 is always
+// UTC, which has no daylight saving transitions, this is exactly the same
+// as adding n*86400 seconds and the time of day is always preserved.
+// Results outside the Time32 range wrap around, as with Add.
+func (t Time32) AddCalendarDays(n int) Time32 {
+	return Time32(uint32(int64(t) + int64(n)*secondsPerDay))
+}
+
+// AddDaysChecked returns t plus the given number of days of 86400 seconds,
+// or ErrOutOfRange if the result does not fit in a Time32. The product is
+// computed in int64, so it does not overflow on 32-bit platforms.
+func (t Time32) AddDaysChecked(days int) (Time32, error) {
+	return fromUnix(int64(t) + int64(days)*secondsPerDay)
+}
+
+// Add returns the time t+d, truncating d to whole seconds.
+// The sum is computed in int64 and then wrapped modulo 2^32, so a result
+// outside the Time32 range wraps around deterministically on every platform:
+// for example Time32(3600).Add(-2*Hour) returns Time32(math.MaxUint32-3599).
+// Use AddOverflow to detect such a wrap.
+func (t Time32) Add(d Duration) Time32 {
+	v, _ := t.AddOverflow(d)
+	return v
+}
+
+// AddOverflow returns the time t+d truncated to whole seconds, together with
+// a flag reporting whether the result overflowed (or underflowed) the uint32
+// range. When the flag is true, the returned value is the wrapped result.
+func (t Time32) AddOverflow(d Duration) (Time32, bool) {
+	v := int64(t) + int64(d/Second)
+	return Time32(uint32(v)), v < 0 || v > math.MaxUint32
+}
+
+// Sub returns the duration t-u, which is negative when t is before u.
+func (t Time32) Sub(u Time32) Duration {
+	return Duration(int64(t)-int64(u)) * Second
+}
+
+// Remaining returns the time left until t, measured from the cached epoch
+// value. The result is negative if t is already in the past, which makes it
+// suitable for TTL and lease expiration checks.
+func (t Time32) Remaining() Duration {
+	return t.Sub(loadSnapshot().epoch)
+}
+
+// AgeBucket returns a histogram label for the age of t measured from the
+// cached epoch value: "<1m", "<5m", "<1h", "<1d" or ">=1d". Times in the
+// future count as "<1m".
+func AgeBucket(t Time32) string {
+	age := -t.Remaining()
+	switch {
+	case age < Minute:
+		return "<1m"
+	case age < 5*Minute:
+		return "<5m"
+	case age < Hour:
+		return "<1h"
+	case age < 24*Hour:
+		return "<1d"
+	}
+	return ">=1d"
+}
+
+// Until32 returns the duration until t, measured from the cached epoch
+// value. It mirrors Until for Time32 deadlines and is shorthand for
+// t.Remaining().
+func Until32(t Time32) Duration {
+	return t.Remaining()
+}
+
+// SecondsOfDay returns the number of seconds elapsed since midnight UTC,
+// in the range [0, 86399].
+func (t Time32) SecondsOfDay() uint32 {
+	return uint32(int64(t) % secondsPerDay)
+}
+
+// DayIndex returns the number of whole days elapsed since the Unix epoch.
+// Together with SecondsOfDay it splits t into a day key and an intraday
+// offset: t == DayIndex()*86400 + SecondsOfDay().
+func (t Time32) DayIndex() uint32 {
+	return uint32(int64(t) / secondsPerDay)
+}
+
+// Truncate returns the result of rounding t down to a multiple of d since
+// the Unix epoch, in UTC. Time32 cannot hold sub-second values, so only
+// whole seconds of d are taken into account and d should be a multiple of
+// Second: if d is shorter than a second (including d <= 0), Truncate
+// returns t unchanged rather than silently using one second.
+func (t Time32) Truncate(d Duration) Time32 {
+	if d < Second {
+		return t
+	}
+	step := uint64(d / Second)
+	return t - Time32(uint64(t)%step)
+}
+
+// Round returns the result of rounding t to the nearest multiple of d
+// since the Unix epoch, in UTC. Halfway values round up, and results past
+// the end of the Time32 range saturate to math.MaxUint32. As with
+// Truncate, only whole seconds of d are taken into account and Round
+// returns t unchanged if d is shorter than a second.
+func (t Time32) Round(d Duration) Time32 {
+	if d < Second {
+		return t
+	}
+	step := uint64(d / Second)
+	r := uint64(t) % step
+	if r+r < step {
+		return t - Time32(r)
+	}
+	if up := uint64(t) + step - r; up <= math.MaxUint32 {
+		return Time32(up)
+	}
+	return math.MaxUint32
+}
+
+// TruncateMinute returns t rounded down to the start of its minute.
+func (t Time32) TruncateMinute() Time32 {
+	return t.Truncate(Minute)
+}
+
+// TruncateHour returns t rounded down to the start of its hour.
+func (t Time32) TruncateHour() Time32 {
+	return t.Truncate(Hour)
+}
+
+// TruncateDay returns t rounded down to midnight UTC of its day.
+func (t Time32) TruncateDay() Time32 {
+	return t.Truncate(24 * Hour)
+}
+
+// StartOfDay returns midnight UTC of the day in which t occurs.
+func (t Time32) StartOfDay() Time32 {
+	return t.TruncateDay()
+}
+
+// MidnightDaysAgo returns midnight UTC n days before today, where today is
+// taken from the cached epoch value. MidnightDaysAgo(0) is the start of
+// today. Cutoffs before the Unix epoch are clamped to Time32(0).
+// It panics if n < 0.
+func MidnightDaysAgo(n int) Time32 {
+	if n < 0 {
+		panic("time32: negative days for MidnightDaysAgo")
+	}
+	day := int64(loadSnapshot().epoch.StartOfDay())
+	if int64(n) > day/secondsPerDay {
+		return 0
+	}
+	return Time32(day - int64(n)*secondsPerDay)
+}
+
+// StartOfWeek returns midnight UTC of the Monday starting the week in
+// which t occurs. Use StartOfWeekOn for weeks starting on another day.
+func (t Time32) StartOfWeek() Time32 {
+	return t.StartOfWeekOn(time.Monday)
+}
+
+// StartOfWeekOn returns midnight UTC of the most recent given weekday at or
+// before t, that is, the start of the week containing t for weeks starting
+// on weekday (e.g. time.Sunday in the US). Weeks that would start before
+// the Unix epoch are clamped to Time32(0).
+func (t Time32) StartOfWeekOn(weekday time.Weekday) Time32 {
+	back := Time32((int(t.Weekday())-int(weekday)+7)%7) * secondsPerDay
+	day := t.StartOfDay()
+	if back > day {
+		return 0
+	}
+	return day - back
+}
+
+// ISOWeek returns the ISO 8601 year and week number in which t occurs.
+// Week ranges from 1 to 53. Jan 01 to Jan 03 of year n might belong to
+// week 52 or 53 of year n-1, and Dec 29 to Dec 31 might belong to week 1
+// of year n+1.
+func (t Time32) ISOWeek() (year, week int) {
+	return t.ToTime().ISOWeek()
+}
+
+// StartOfISOWeek returns midnight UTC of the Monday that begins the ISO
+// week containing t, which may fall in the previous calendar year. As
+// with StartOfWeek, the first days of 1970 are clamped to Time32(0).
+func (t Time32) StartOfISOWeek() Time32 {
+	return t.StartOfWeekOn(time.Monday)
+}
+
+// WeekOfMonth returns the week of the UTC month in which t occurs, from 1
+// to 6, with weeks starting on Monday as in StartOfWeek. Week 1 runs from
+// the 1st to the first Sunday, so it may be shorter than seven days.
+func (t Time32) WeekOfMonth() int {
+	return t.WeekOfMonthOn(time.Monday)
+}
+
+// WeekOfMonthOn is like WeekOfMonth, for weeks starting on the given
+// weekday (e.g. time.Sunday in the US).
+func (t Time32) WeekOfMonthOn(start time.Weekday) int {
+	day := t.Day()
+	first := Time32(int64(t) - int64(day-1)*secondsPerDay).Weekday()
+	offset := (int(first) - int(start) + 7) % 7
+	return (day-1+offset)/7 + 1
+}
+
+// Compare compares t and u. It returns -1 if t is before u, 0 if they are
+// the same instant and +1 if t is after u.
+func (t Time32) Compare(u Time32) int {
+	switch {
+	case t < u:
+		return -1
+	case t > u:
+		return 1
+	}
+	return 0
+}
+
+// EqualWithin reports whether t and u are at most tol apart. The tolerance
+// is truncated to whole seconds, so EqualWithin(u, 0) behaves as t == u.
+func (t Time32) EqualWithin(u Time32, tol Duration) bool {
+	diff := int64(t) - int64(u)
+	if diff < 0 {
+		diff = -diff
+	}
+	return diff <= int64(tol/Second)
+}
+
+// ToTime returns t as a standard library time.Time in UTC.
+func (t Time32) ToTime() time.Time {
+	return time.Unix(int64(t), 0).UTC()
+}
+
+// ToTimeUTC returns t as a standard library time.Time whose location is
+// guaranteed to be time.UTC, so downstream formatting never leaks the
+// local time zone. It is equivalent to ToTime.
+func (t Time32) ToTimeUTC() time.Time {
+	return t.ToTime()
+}
+
+// IsUTC reports whether t is in the time.UTC location.
+func IsUTC(t time.Time) bool {
+	return t.Location() == time.UTC
+}
+
+// Date returns the year, month, and day in which t occurs, in UTC.
+func (t Time32) Date() (year int, month time.Month, day int) {
+	var m Month
+	year, m, day, _ = absDate(t.abs(), true)
+	return year, time.Month(m), day
+}
+
+// Year returns the year in which t occurs, in UTC.
+func (t Time32) Year() int {
+	year, _, _, _ := absDate(t.abs(), false)
+	return year
+}
+
+// Day returns the day of the month specified by t, in UTC.
+func (t Time32) Day() int {
+	_, _, day, _ := absDate(t.abs(), true)
+	return day
+}
+
+// YearDay returns the day of the year specified by t, in UTC, in the range
+// [1,365] for non-leap years, and [1,366] in leap years.
+func (t Time32) YearDay() int {
+	_, _, _, yday := absDate(t.abs(), false)
+	return yday + 1
+}
+
+// Month returns the month of the year specified by t, in UTC.
+func (t Time32) Month() time.Month {
+	_, month, _, _ := absDate(t.abs(), true)
+	return time.Month(month)
+}
+
+// Weekday returns the day of the week specified by t, in UTC.
+func (t Time32) Weekday() time.Weekday {
+	return time.Weekday(absWeekday(t.abs()))
+}
+
+// DaysInMonth returns the number of days of the month in which t occurs, in UTC.
+func (t Time32) DaysInMonth() int {
+	year, month, _ := t.Date()
+	return daysIn(Month(month), year)
+}
+
+// SecondsInMonth returns the length, in seconds, of the month in which t
+// occurs. UTC has no daylight saving transitions, so every day lasts
+// exactly 86400 seconds.
+func (t Time32) SecondsInMonth() int64 {
+	return int64(t.DaysInMonth()) * secondsPerDay
+}
+
+// IsWeekend reports whether t falls on a Saturday or a Sunday, in UTC.
+func (t Time32) IsWeekend() bool {
+	d := t.Weekday()
+	return d == time.Saturday || d == time.Sunday
+}
+
+// IsWeekday reports whether t falls between Monday and Friday, in UTC.
+func (t Time32) IsWeekday() bool {
+	return !t.IsWeekend()
+}
+
+// MonthName returns the English name of the month specified by t ("January", "February", ...).
+func (t Time32) MonthName() string {
+	return longMonthNames[t.Month()-1]
+}
+
+// WeekdayName returns the English name of the day specified by t ("Sunday", "Monday", ...).
+func (t Time32) WeekdayName() string {
+	return longDayNames[t.Weekday()]
+}
+
+// InOffset returns the calendar date and clock time of t as seen in a fixed
+// offset of offsetSeconds east of UTC (e.g. 19800 for UTC+5:30), for
+// presentation purposes only. It does not load any time zone database nor
+// create a *Location. It panics if the offset is beyond ±14 hours.
+func (t Time32) InOffset(offsetSeconds int) (year int, month time.Month, day, hour, min, sec int) {
+	if offsetSeconds < -14*secondsPerHour || offsetSeconds > 14*secondsPerHour {
+		panic("time32: offset out of range")
+	}
+	abs := uint64(int64(t.abs()) + int64(offsetSeconds))
+	var m Month
+	year, m, day, _ = absDate(abs, true)
+	hour, min, sec = absClock(abs)
+	return year, time.Month(m), day, hour, min, sec
+}
+
+// abs returns t as an absolute time.
+func (t Time32) abs() uint64 {
+	return uint64(int64(t) + (unixToInternal + internalToAbsolute))
+}
+
+// MonthsBetween returns the number of whole calendar months elapsed from a
+// to b, in UTC. A month is complete once the day of month of a is reached,
+// so from January 31 to February 28 no whole month has elapsed yet.
+// The result is negative when b is before a.
+func MonthsBetween(a, b Time32) int {
+	ay, am, ad := a.Date()
+	by, bm, bd := b.Date()
+	months := (by-ay)*12 + int(bm-am)
+	switch {
+	case months > 0 && bd < ad:
+		months--
+	case months < 0 && bd > ad:
+		months++
+	}
+	return months
+}
+
+// BusinessSeconds returns how many seconds of [start, end) fall on a
+// weekday (Monday to Friday) between openHour and closeHour, in UTC.
+// For 09:00 to 17:00 pass 9 and 17. It returns 0 if end is not after start.
+// It panics unless 0 <= openHour <= closeHour <= 24.
+func BusinessSeconds(start, end Time32, openHour, closeHour int) int64 {
+	if openHour < 0 || openHour > closeHour || closeHour > 24 {
+		panic("time32: invalid business hours")
+	}
+	var total int64
+	for day := int64(start.StartOfDay()); day < int64(end); day += secondsPerDay {
+		if !Time32(day).IsWeekday() {
+			continue
+		}
+		from := day + int64(openHour)*secondsPerHour
+		to := day + int64(closeHour)*secondsPerHour
+		if from < int64(start) {
+			from = int64(start)
+		}
+		if to > int64(end) {
+			to = int64(end)
+		}
+		if to > from {
+			total += to - from
+		}
+	}
+	return total
+}
+
+// SameDay reports whether a and b fall on the same calendar day, in UTC.
+func SameDay(a, b Time32) bool {
+	return a/secondsPerDay == b/secondsPerDay
+}
+
+// SameMonth reports whether a and b fall in the same calendar month of the
+// same year, in UTC.
+func SameMonth(a, b Time32) bool {
+	ay, am, _ := a.Date()
+	by, bm, _ := b.Date()
+	return ay == by && am == bm
+}
+
+// SameYear reports whether a and b fall in the same calendar year, in UTC.
+func SameYear(a, b Time32) bool {
+	return a.Year() == b.Year()
+}
+
+// YearsUntil returns the number of complete calendar years from t to u, in
+// UTC, such as the age on date u of someone born on date t. Only dates are
+// compared, not times of day. As with NextAnniversary, a February 29
+// anniversary is reached on March 1 in years that are not leap years.
+// The result is negative when u is before t.
+func (t Time32) YearsUntil(u Time32) int {
+	if u < t {
+		return -u.YearsUntil(t)
+	}
+	_, m, d := t.Date()
+	uy, um, ud := u.Date()
+	if m == time.February && d == 29 && !isLeap(uy) {
+		m, d = time.March, 1
+	}
+	years := uy - t.Year()
+	if um < m || um == m && ud < d {
+		years--
+	}
+	return years
+}
+
+// NextAnniversary returns midnight UTC of the next date with the same month
+// and day as t, measured from the cached epoch value. Today counts as the
+// next anniversary if it matches. A February 29 falls on March 1 in years
+// that are not leap years. It returns 0 if the next anniversary does not
+// fit in a Time32.
+func (t Time32) NextAnniversary() Time32 {
+	_, month, day := t.Date()
+	today := loadSnapshot().epoch.StartOfDay()
+	year := today.Year()
+	for {
+		m, d := month, day
+		if m == time.February && d == 29 && !isLeap(year) {
+			m, d = time.March, 1
+		}
+		next, err := Date32(year, m, d, 0, 0, 0)
+		if err != nil {
+			return 0
+		}
+		if next >= today {
+			return next
+		}
+		year++
+	}
+}
+
+// Overlaps reports whether the half-open intervals [aStart, aEnd) and
+// [bStart, bEnd) intersect. Intervals that only touch at a boundary do not
+// overlap. An interval whose start comes after its end is swapped first.
+func Overlaps(aStart, aEnd, bStart, bEnd Time32) bool {
+	as, ae, bs, be := int64(aStart), int64(aEnd), int64(bStart), int64(bEnd)
+	if as > ae {
+		as, ae = ae, as
+	}
+	if bs > be {
+		bs, be = be, bs
+	}
+	return as < be && bs < ae
+}
+
+// Hash returns a well distributed 64 bit hash of t, computed with the
+// splitmix64 finalizer, so sequential timestamps spread evenly across the
+// buckets of custom hash maps. It is deterministic and fast, but NOT a
+// cryptographic hash.
+func (t Time32) Hash() uint64 {
+	z := uint64(t) + 0x9e3779b97f4a7c15
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+	return z ^ (z >> 31)
+}
+
+// FromJSMillis converts a JavaScript timestamp, as returned by Date.now()
+// (milliseconds since the Unix epoch), into a Time32. The sub-second part
+// is truncated, so the conversion loses up to 999 milliseconds.
+// It returns ErrOutOfRange if the value does not fit in a Time32.
+func FromJSMillis(ms int64) (Time32, error) {
+	if ms < 0 {
+		return 0, ErrOutOfRange
+	}
+	return fromUnix(ms / 1000)
+}
+
+// JSMillis returns t as a JavaScript timestamp, in milliseconds since the
+// Unix epoch.
+func (t Time32) JSMillis() int64 {
+	return int64(t) * 1000
+}
+
+// ParseUnixAuto converts a Unix timestamp of unknown unit into a Time32,
+// guessing the unit from the magnitude of v:
+//
+//	v < 1e11          seconds      (up to 11 digits, e.g. 1588228661)
+//	1e11 <= v < 1e14  milliseconds (12-14 digits, e.g. 1588228661000)
+//	1e14 <= v < 1e17  microseconds (15-17 digits)
+//	v >= 1e17         nanoseconds  (18-19 digits, e.g. 1588228661000000000)
+//
+// The heuristic assumes timestamps close to the present: a millisecond value
+// earlier than 1973-03-03, or a second value later than year 5138, is
+// misinterpreted. Sub-second parts are truncated. It returns ErrOutOfRange
+// if the normalized value does not fit in a Time32.
+func ParseUnixAuto(v int64) (Time32, error) {
+	switch {
+	case v < 0:
+		return 0, ErrOutOfRange
+	case v < 1e11:
+		return fromUnix(v)
+	case v < 1e14:
+		return fromUnix(v / 1e3)
+	case v < 1e17:
+		return fromUnix(v / 1e6)
+	default:
+		return fromUnix(v / 1e9)
+	}
+}
+
+// fileTimeEpochOffset is the number of seconds between the Windows FILETIME
+// epoch (1601-01-01 00:00:00 UTC) and the Unix epoch.
+const fileTimeEpochOffset = 11644473600
+
+// FileTime returns t as a Windows FILETIME value: the number of 100
+// nanosecond intervals since January 1, 1601 UTC.
+func (t Time32) FileTime() uint64 {
+	return (uint64(t) + fileTimeEpochOffset) * 1e7
+}
+
+// FromFileTime converts a Windows FILETIME value into a Time32, truncating
+// the sub-second part. It returns ErrOutOfRange if the value does not fit
+// in a Time32.
+func FromFileTime(ft uint64) (Time32, error) {
+	sec := ft / 1e7
+	if sec < fileTimeEpochOffset || sec-fileTimeEpochOffset > math.MaxUint32 {
+		return 0, ErrOutOfRange
+	}
+	return Time32(sec - fileTimeEpochOffset), nil
+}
+
+// ToTime32Round converts t into a Time32 rounded to the nearest second, so
+// a sub-second part of 500ms or more rounds up, instead of being truncated
+// as Time32(t.Unix()) does. Instants outside the Time32 range are clamped
+// to its bounds.
+func ToTime32Round(t Time) Time32 {
+	sec := t.Unix()
+	if t.Nanosecond() >= 5e8 {
+		sec++
+	}
+	switch {
+	case sec < 0:
+		return 0
+	case sec > math.MaxUint32:
+		return math.MaxUint32
+	}
+	return Time32(sec)
+}
+
+// Time32Range returns the earliest and latest instants a Time32 can hold,
+// 1970-01-01T00:00:00Z and 2106-02-07T06:28:15Z, in UTC.
+func Time32Range() (min, max time.Time) {
+	return time.Unix(0, 0).UTC(), time.Unix(math.MaxUint32, 0).UTC()
+}
+
+// DeadlineFromContext returns the deadline of ctx as a Time32, truncated
+// to the second. The boolean is false if ctx has no deadline. Deadlines
+// outside the Time32 range are clamped to its bounds, so a deadline far in
+// the future never turns into one in the past.
+func DeadlineFromContext(ctx context.Context) (Time32, bool) {
+	d, ok := ctx.Deadline()
+	if !ok {
+		return 0, false
+	}
+	switch sec := d.Unix(); {
+	case sec < 0:
+		return 0, true
+	case sec > math.MaxUint32:
+		return math.MaxUint32, true
+	default:
+		return Time32(sec), true
+	}
+}
+
+// fromUnix converts a Unix time in seconds into a Time32, returning
+// ErrOutOfRange if it does not fit in 32 bits.
+func fromUnix(sec int64) (Time32, error) {
+	if sec < 0 || sec > math.MaxUint32 {
+		return 0, ErrOutOfRange
+	}
+	return Time32(sec), nil
 }
 
 func (t *Time32) setTime(now uint32) {
 	*t = Time32(now)
 }
 
-// Epoch Returns current server epoch millis time without
-// GC dealing with *loc pointers
+// Date32 returns the Time32 corresponding to yyyy-mm-dd hh:mm:ss in UTC.
+// The month, day, hour, min and sec values may be outside their usual
+// ranges and will be normalized the same way Date does. For example,
+// month 13 of 2020 converts to January 2021.
+// It returns ErrOutOfRange if the result does not fit in a Time32.
+func Date32(year int, month time.Month, day, hour, min, sec int) (Time32, error) {
+	return fromUnix(Date(year, Month(month), day, hour, min, sec, 0).Unix())
+}
+
+// Epoch Returns current server epoch time, in seconds, without
+// GC dealing with *loc pointers. It reads the same clock as Now and
+// returns the same Unix second that Now().Unix() would.
 func Epoch() Time32 {
-	return Time32(get_now())
-}
-
-// Epoch Returns current server epoch millis time without
-// GC dealing with *loc pointers
-func get_now() uint32 {
-	sec, nsec, mono := time_now()
-	mono -= startNano
-	var wall uint64
-	var ext int64
-	var unsec = uint64(nsec)
-	sec += unixToInternal - minWall
-	var usec = uint64(sec)
-	if usec>>33 != 0 {
-		wall, ext = unsec, sec+minWall
+	if ClockOffset() != 0 || loadClock() != nil {
+		return Time32(Now().Unix())
+	}
+	sec, _, _ := wallNow()
+	return Time32(sec)
+}
+
+// EpochMillisOfDay returns the milliseconds elapsed since midnight UTC,
+// from 0 to 86,399,999, packed in a Time32. Milliseconds since the Unix
+// epoch do not fit in 32 bits, so use EpochDayMillis when the day matters.
+func EpochMillisOfDay() Time32 {
+	_, millis := EpochDayMillis()
+	return millis
+}
+
+// EpochDayMillis returns the number of days since the Unix epoch together
+// with the milliseconds elapsed since midnight UTC of that day, both from
+// a single clock read. The full instant in Unix milliseconds is
+// int64(day)*86400000 + int64(millis).
+func EpochDayMillis() (day uint32, millis Time32) {
+	var sec int64
+	var nsec int32
+	if ClockOffset() != 0 || loadClock() != nil {
+		t := Now()
+		sec, nsec = t.Unix(), int32(t.Nanosecond())
 	} else {
-		wall, ext = hasMonotonic|usec<<nsecShift|unsec, mono
+		sec, nsec, _ = wallNow()
 	}
-	tsec := ext
-	if wall&hasMonotonic != 0 {
-		tsec = wallToInternal + int64(wall<<1>>(nsecShift+1))
+	return uint32(sec / secondsPerDay), Time32((sec%secondsPerDay)*1000 + int64(nsec)/1e6)
+}
+
+// NowBoth returns the current time both as a Time and as a Time32, reading
+// the clock only once. Both values always agree on the Unix second.
+func NowBoth() (Time, Time32) {
+	if ClockOffset() != 0 || loadClock() != nil {
+		t := Now()
+		return t, Time32(t.Unix())
+	}
+	sec, nsec, mono := wallNow()
+	return nowTime(sec, nsec, mono), Time32(sec)
+}
+
+// coarseWindow is the maximum monotonic time, in nanoseconds, during which
+// EpochCoarse reuses its last wall clock reading
+const coarseWindow = int64(Millisecond)
+
+// EpochCoarse state: the wall clock seconds of the last real reading and
+// the monotonic clock reading taken along with it
+var (
+	coarseSec  int64
+	coarseMono int64
+)
+
+// EpochCoarse is like Epoch, but it only reads the wall clock when more
+// than 1ms of monotonic time elapsed since its previous wall clock reading,
+// reusing that reading in between. Reading the monotonic clock is cheaper,
+// so this amortizes the cost of Epoch in tight loops while staying
+// accurate to the second (the returned value lags by at most 1ms).
+func EpochCoarse() Time32 {
+	if ClockOffset() != 0 || loadClock() != nil {
+		return Epoch()
+	}
+	mono := nanotime()
+	if mono-atomic.LoadInt64(&coarseMono) < coarseWindow {
+		return Time32(atomic.LoadInt64(&coarseSec))
+	}
+	sec, _, _ := wallNow()
+	atomic.StoreInt64(&coarseSec, sec)
+	atomic.StoreInt64(&coarseMono, mono)
+	return Time32(sec)
+}
+
+// epochSeqState packs the last second returned by EpochSeq in the high
+// 32 bits and its sequence number in the low 32 bits
+var epochSeqState uint64
+
+// EpochSeq returns the current epoch together with a sequence number that
+// starts at 0 on every new second and increases by one on each call within
+// that second. Combining both as uint64(epoch)<<32 | uint64(seq) gives a
+// lock-free, strictly increasing 8 byte key that preserves the ordering of
+// events happening in the same second. If the clock steps backward, the
+// last returned second is kept and its sequence continues, so keys never
+// go backward.
+func EpochSeq() (Time32, uint32) {
+	now := uint64(Epoch())
+	for {
+		old := atomic.LoadUint64(&epochSeqState)
+		next := now << 32
+		if old>>32 >= now {
+			next = old + 1
+		}
+		if atomic.CompareAndSwapUint64(&epochSeqState, old, next) {
+			return Time32(next >> 32), uint32(next)
+		}
 	}
-	return uint32(tsec + internalToUnix)
 }
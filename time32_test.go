@@ -0,0 +1,516 @@
+//
+// Created by zerjioang
+// https://github/zerjioang
+// Copyright (c) 2020. All rights reserved.
+//
+// SPDX-License-Identifier: GPL-3.0
+//
+
+package time32
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"math"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestTime32(t *testing.T) {
+	t.Run("add-overflow-in-range", func(t *testing.T) {
+		v, overflow := Time32(100).AddOverflow(20 * Second)
+		assert.False(t, overflow)
+		assert.Equal(t, Time32(120), v)
+	})
+	t.Run("add-overflow-upper-bound", func(t *testing.T) {
+		v, overflow := Time32(math.MaxUint32).AddOverflow(0)
+		assert.False(t, overflow)
+		assert.Equal(t, Time32(math.MaxUint32), v)
+
+		v, overflow = Time32(math.MaxUint32).AddOverflow(2 * Second)
+		assert.True(t, overflow)
+		assert.Equal(t, Time32(1), v)
+	})
+	t.Run("add-overflow-lower-bound", func(t *testing.T) {
+		v, overflow := Time32(1).AddOverflow(-Second)
+		assert.False(t, overflow)
+		assert.Equal(t, Time32(0), v)
+
+		v, overflow = Time32(0).AddOverflow(-Second)
+		assert.True(t, overflow)
+		assert.Equal(t, Time32(math.MaxUint32), v)
+	})
+	t.Run("date32", func(t *testing.T) {
+		cases := [][6]int{
+			{2020, 4, 30, 6, 37, 41},
+			{1970, 1, 1, 0, 0, 0},
+			{2106, 2, 7, 6, 28, 15},
+			{2020, 13, 1, 0, 0, 0},
+			{2020, 2, 30, 25, 61, -1},
+		}
+		for _, c := range cases {
+			v, err := Date32(c[0], time.Month(c[1]), c[2], c[3], c[4], c[5])
+			assert.NoError(t, err)
+			expected := time.Date(c[0], time.Month(c[1]), c[2], c[3], c[4], c[5], 0, time.UTC).Unix()
+			assert.Equal(t, Time32(expected), v)
+		}
+	})
+	t.Run("date32-out-of-range", func(t *testing.T) {
+		_, err := Date32(1969, time.December, 31, 23, 59, 59)
+		assert.Equal(t, ErrOutOfRange, err)
+		_, err = Date32(2106, time.February, 7, 6, 28, 16)
+		assert.Equal(t, ErrOutOfRange, err)
+	})
+	t.Run("equal-within", func(t *testing.T) {
+		a := Time32(1588228661)
+		assert.True(t, a.EqualWithin(a, 0))
+		assert.True(t, a.EqualWithin(a+5, 5*Second))
+		assert.True(t, (a+5).EqualWithin(a, 5*Second))
+		assert.True(t, a.EqualWithin(a+4, 5*Second))
+		assert.False(t, a.EqualWithin(a+6, 5*Second))
+		assert.False(t, (a+6).EqualWithin(a, 5*Second))
+	})
+	t.Run("weekday-and-month-names", func(t *testing.T) {
+		// 2020-04-30T06:37:41Z
+		tt := Time32(1588228661)
+		assert.Equal(t, time.Thursday, tt.Weekday())
+		assert.Equal(t, time.April, tt.Month())
+		assert.Equal(t, "Thursday", tt.WeekdayName())
+		assert.Equal(t, "April", tt.MonthName())
+		assert.Equal(t, 0.0, testing.AllocsPerRun(10, func() {
+			_ = tt.WeekdayName()
+			_ = tt.MonthName()
+		}))
+	})
+	t.Run("to-time-is-utc", func(t *testing.T) {
+		tt := Time32(1588228661)
+		assert.Equal(t, time.UTC, tt.ToTime().Location())
+		assert.True(t, IsUTC(tt.ToTime()))
+		assert.True(t, IsUTC(tt.ToTimeUTC()))
+		assert.False(t, IsUTC(tt.ToTime().In(time.FixedZone("UTC+1", 3600))))
+		assert.Equal(t, int64(1588228661), tt.ToTimeUTC().Unix())
+	})
+	t.Run("overlaps", func(t *testing.T) {
+		// fully disjoint
+		assert.False(t, Overlaps(10, 20, 30, 40))
+		assert.False(t, Overlaps(30, 40, 10, 20))
+		// touching at boundary
+		assert.False(t, Overlaps(10, 20, 20, 30))
+		assert.False(t, Overlaps(20, 30, 10, 20))
+		// partial overlap
+		assert.True(t, Overlaps(10, 25, 20, 30))
+		assert.True(t, Overlaps(20, 30, 10, 25))
+		// containment
+		assert.True(t, Overlaps(10, 40, 20, 30))
+		assert.True(t, Overlaps(20, 30, 10, 40))
+		// swapped bounds
+		assert.True(t, Overlaps(25, 10, 30, 20))
+	})
+	t.Run("add", func(t *testing.T) {
+		assert.Equal(t, Time32(3660), Time32(3600).Add(Minute))
+		assert.Equal(t, Time32(3600), Time32(3600).Add(999*Millisecond))
+		assert.Equal(t, Time32(1800), Time32(3600).Add(-30*Minute))
+	})
+	t.Run("add-negative-wraps", func(t *testing.T) {
+		for i := 0; i < 3; i++ {
+			v := Time32(3600).Add(-2 * Hour)
+			assert.Equal(t, Time32(math.MaxUint32-3599), v)
+			assert.Equal(t, Time32(4294963696), v)
+		}
+	})
+	t.Run("truncate-helpers", func(t *testing.T) {
+		// 2020-04-30T06:37:41Z
+		tt := Time32(1588228661)
+		assert.Equal(t, "2020-04-30T06:37:00Z", tt.TruncateMinute().Format(time.RFC3339))
+		assert.Equal(t, "2020-04-30T06:00:00Z", tt.TruncateHour().Format(time.RFC3339))
+		assert.Equal(t, "2020-04-30T00:00:00Z", tt.TruncateDay().Format(time.RFC3339))
+		assert.Equal(t, tt.TruncateHour(), tt.Truncate(Hour))
+		assert.Equal(t, tt, tt.Truncate(0))
+	})
+	t.Run("truncate-round", func(t *testing.T) {
+		// 2020-04-30T06:37:41Z
+		tt := Time32(1588228661)
+		assert.Equal(t, tt, tt.Truncate(500*Millisecond))
+		assert.Equal(t, tt, tt.Round(500*Millisecond))
+		assert.Equal(t, tt, tt.Round(0))
+		assert.Equal(t, tt, tt.Round(-Minute))
+		assert.Equal(t, Time32(1588228650), tt.Truncate(90*Second))
+		assert.Equal(t, Time32(1588228650), tt.Round(90*Second))
+		assert.Equal(t, Time32(1588228740), Time32(1588228696).Round(90*Second))
+		assert.Equal(t, Time32(1588228740), Time32(1588228695).Round(90*Second))
+		assert.Equal(t, tt.TruncateHour()+3600, tt.Round(Hour))
+		assert.Equal(t, tt, tt.Truncate(1500*Millisecond))
+		assert.Equal(t, Time32(0), tt.Truncate(200*365*24*Hour))
+		assert.Equal(t, Time32(math.MaxUint32), Time32(math.MaxUint32).Round(100*Second))
+	})
+	t.Run("sub", func(t *testing.T) {
+		assert.Equal(t, Minute, Time32(160).Sub(100))
+		assert.Equal(t, -Minute, Time32(100).Sub(160))
+	})
+	t.Run("remaining", func(t *testing.T) {
+		SetClock(NewManualClock(time.Unix(1588228661, 0)))
+		defer SetClock(nil)
+
+		assert.Equal(t, 30*Second, Time32(1588228691).Remaining())
+		assert.Equal(t, -Hour, Time32(1588228661-3600).Remaining())
+	})
+	t.Run("epoch-seq", func(t *testing.T) {
+		clock := NewManualClock(time.Unix(1588228661, 0))
+		SetClock(clock)
+		defer SetClock(nil)
+		atomic.StoreUint64(&epochSeqState, 0)
+
+		epoch, seq := EpochSeq()
+		assert.Equal(t, Time32(1588228661), epoch)
+		for i := 0; i < 1000; i++ {
+			e, s := EpochSeq()
+			assert.Equal(t, epoch, e)
+			assert.Equal(t, seq+1, s)
+			seq = s
+		}
+		clock.Advance(time.Second)
+		epoch, seq = EpochSeq()
+		assert.Equal(t, Time32(1588228662), epoch)
+		assert.Equal(t, uint32(0), seq)
+		// a backward step keeps keys increasing
+		clock.Advance(-time.Second)
+		epoch, seq = EpochSeq()
+		assert.Equal(t, Time32(1588228662), epoch)
+		assert.Equal(t, uint32(1), seq)
+	})
+	t.Run("in-offset", func(t *testing.T) {
+		// 2020-04-30T06:37:41Z
+		tt := Time32(1588228661)
+		year, month, day, hour, min, sec := tt.InOffset(5*3600 + 30*60)
+		assert.Equal(t, []int{2020, 4, 30, 12, 7, 41}, []int{year, int(month), day, hour, min, sec})
+		year, month, day, hour, min, sec = tt.InOffset(-8 * 3600)
+		assert.Equal(t, []int{2020, 4, 29, 22, 37, 41}, []int{year, int(month), day, hour, min, sec})
+		assert.Panics(t, func() { tt.InOffset(15 * 3600) })
+		assert.Panics(t, func() { tt.InOffset(-15 * 3600) })
+	})
+	t.Run("hash", func(t *testing.T) {
+		assert.Equal(t, Time32(1588228661).Hash(), Time32(1588228661).Hash())
+		seen := make(map[uint64]struct{})
+		buckets := make([]int, 16)
+		const n = 100000
+		for i := Time32(1588228661); i < 1588228661+n; i++ {
+			h := i.Hash()
+			seen[h] = struct{}{}
+			buckets[h%16]++
+		}
+		assert.Len(t, seen, n)
+		for _, b := range buckets {
+			assert.InDelta(t, n/16, b, n/160)
+		}
+	})
+	t.Run("js-millis", func(t *testing.T) {
+		v, err := FromJSMillis(1588228661789)
+		assert.NoError(t, err)
+		assert.Equal(t, Time32(1588228661), v)
+		assert.Equal(t, int64(1588228661000), v.JSMillis())
+
+		_, err = FromJSMillis((math.MaxUint32 + 1) * 1000)
+		assert.Equal(t, ErrOutOfRange, err)
+		_, err = FromJSMillis(-1)
+		assert.Equal(t, ErrOutOfRange, err)
+	})
+	t.Run("parse-unix-auto", func(t *testing.T) {
+		for _, v := range []int64{1588228661, 1588228661123, 1588228661123456, 1588228661123456789} {
+			tt, err := ParseUnixAuto(v)
+			assert.NoError(t, err)
+			assert.Equal(t, Time32(1588228661), tt)
+		}
+		_, err := ParseUnixAuto(-1)
+		assert.Equal(t, ErrOutOfRange, err)
+		_, err = ParseUnixAuto(99999999999)
+		assert.Equal(t, ErrOutOfRange, err)
+	})
+	t.Run("months-between", func(t *testing.T) {
+		date := func(year int, month time.Month, day int) Time32 {
+			v, err := Date32(year, month, day, 0, 0, 0)
+			assert.NoError(t, err)
+			return v
+		}
+		assert.Equal(t, 1, MonthsBetween(date(2020, 4, 15), date(2020, 5, 15)))
+		assert.Equal(t, 0, MonthsBetween(date(2020, 4, 15), date(2020, 5, 14)))
+		assert.Equal(t, 0, MonthsBetween(date(2020, 1, 31), date(2020, 2, 29)))
+		assert.Equal(t, 2, MonthsBetween(date(2020, 11, 20), date(2021, 1, 25)))
+		assert.Equal(t, 13, MonthsBetween(date(2019, 12, 1), date(2021, 1, 1)))
+		assert.Equal(t, -1, MonthsBetween(date(2020, 5, 15), date(2020, 4, 15)))
+		assert.Equal(t, 0, MonthsBetween(date(2020, 5, 14), date(2020, 4, 15)))
+	})
+	t.Run("year-day", func(t *testing.T) {
+		jan1, _ := Date32(2021, time.January, 1, 0, 0, 0)
+		dec31, _ := Date32(2021, time.December, 31, 23, 59, 59)
+		leapDec31, _ := Date32(2020, time.December, 31, 12, 0, 0)
+		assert.Equal(t, 1, jan1.YearDay())
+		assert.Equal(t, 365, dec31.YearDay())
+		assert.Equal(t, 366, leapDec31.YearDay())
+		assert.Equal(t, time.Unix(1588228661, 0).UTC().YearDay(), Time32(1588228661).YearDay())
+	})
+	t.Run("file-time", func(t *testing.T) {
+		// 2020-04-30T06:37:41Z
+		assert.Equal(t, uint64(132327022610000000), Time32(1588228661).FileTime())
+		v, err := FromFileTime(132327022615000000)
+		assert.NoError(t, err)
+		assert.Equal(t, Time32(1588228661), v)
+
+		// Unix epoch boundary
+		assert.Equal(t, uint64(116444736000000000), Time32(0).FileTime())
+		v, err = FromFileTime(116444736000000000)
+		assert.NoError(t, err)
+		assert.Equal(t, Time32(0), v)
+		_, err = FromFileTime(116444736000000000 - 1)
+		assert.Equal(t, ErrOutOfRange, err)
+		_, err = FromFileTime(Time32(math.MaxUint32).FileTime() + 1e7)
+		assert.Equal(t, ErrOutOfRange, err)
+	})
+	t.Run("day-split", func(t *testing.T) {
+		// 2020-04-30T06:37:41Z
+		tt := Time32(1588228661)
+		assert.Equal(t, uint32(6*3600+37*60+41), tt.SecondsOfDay())
+		assert.Equal(t, uint32(18382), tt.DayIndex())
+		for _, v := range []Time32{0, 86399, 86400, 1588228661, math.MaxUint32} {
+			assert.Equal(t, uint32(v), v.DayIndex()*86400+v.SecondsOfDay())
+		}
+	})
+	t.Run("until32", func(t *testing.T) {
+		clock := NewManualClock(time.Unix(1588228661, 0))
+		SetClock(clock)
+		defer SetClock(nil)
+
+		deadline := Time32(1588228661 + 90)
+		assert.Equal(t, 90*Second, Until32(deadline))
+		clock.Advance(2 * time.Minute)
+		assert.Equal(t, -30*Second, Until32(deadline))
+	})
+	t.Run("add-calendar-days", func(t *testing.T) {
+		// 2020-04-30T06:37:41Z
+		tt := Time32(1588228661)
+		assert.Equal(t, "2020-05-01T06:37:41Z", tt.AddCalendarDays(1).String())
+		assert.Equal(t, "2020-03-31T06:37:41Z", tt.AddCalendarDays(-30).String())
+		assert.Equal(t, "2021-02-28T06:37:41Z", tt.AddCalendarDays(304).String())
+		assert.Equal(t, tt.AddDate(10), tt.AddCalendarDays(10))
+	})
+	t.Run("to-time32-round", func(t *testing.T) {
+		assert.Equal(t, Time32(1588228661), ToTime32Round(Unix(1588228661, 4e8)))
+		assert.Equal(t, Time32(1588228662), ToTime32Round(Unix(1588228661, 6e8)))
+		assert.Equal(t, Time32(1588228662), ToTime32Round(Unix(1588228661, 5e8)))
+		assert.Equal(t, Time32(0), ToTime32Round(Unix(-10, 0)))
+		assert.Equal(t, Time32(math.MaxUint32), ToTime32Round(Unix(math.MaxUint32, 6e8)))
+	})
+	t.Run("weekend", func(t *testing.T) {
+		saturday, _ := Date32(2020, time.May, 2, 23, 59, 59)
+		sunday, _ := Date32(2020, time.May, 3, 0, 0, 0)
+		wednesday, _ := Date32(2020, time.April, 29, 12, 0, 0)
+		assert.True(t, saturday.IsWeekend())
+		assert.False(t, saturday.IsWeekday())
+		assert.True(t, sunday.IsWeekend())
+		assert.False(t, wednesday.IsWeekend())
+		assert.True(t, wednesday.IsWeekday())
+	})
+	t.Run("start-of-week", func(t *testing.T) {
+		// Thursday 2020-04-30T06:37:41Z
+		tt := Time32(1588228661)
+		assert.Equal(t, "2020-04-30T00:00:00Z", tt.StartOfDay().String())
+		assert.Equal(t, "2020-04-27T00:00:00Z", tt.StartOfWeek().String())
+		assert.Equal(t, "2020-04-27T00:00:00Z", tt.StartOfWeekOn(time.Monday).String())
+		assert.Equal(t, "2020-04-26T00:00:00Z", tt.StartOfWeekOn(time.Sunday).String())
+		assert.Equal(t, "2020-04-30T00:00:00Z", tt.StartOfWeekOn(time.Thursday).String())
+		// Sunday 2020-05-03
+		sunday, _ := Date32(2020, time.May, 3, 15, 0, 0)
+		assert.Equal(t, "2020-04-27T00:00:00Z", sunday.StartOfWeek().String())
+		assert.Equal(t, "2020-05-03T00:00:00Z", sunday.StartOfWeekOn(time.Sunday).String())
+		assert.Equal(t, Time32(0), Time32(0).StartOfWeek())
+	})
+	t.Run("start-of-iso-week", func(t *testing.T) {
+		// Friday 2021-01-01 belongs to ISO week 53 of 2020
+		tt, _ := Date32(2021, time.January, 1, 12, 0, 0)
+		year, week := tt.ISOWeek()
+		assert.Equal(t, 2020, year)
+		assert.Equal(t, 53, week)
+		start := tt.StartOfISOWeek()
+		assert.Equal(t, "2020-12-28T00:00:00Z", start.String())
+		assert.Equal(t, time.Monday, start.Weekday())
+		year, week = start.ISOWeek()
+		assert.Equal(t, 2020, year)
+		assert.Equal(t, 53, week)
+		// Monday 2024-12-30 belongs to ISO week 1 of 2025
+		tt, _ = Date32(2024, time.December, 31, 23, 59, 59)
+		year, week = tt.ISOWeek()
+		assert.Equal(t, 2025, year)
+		assert.Equal(t, 1, week)
+		assert.Equal(t, "2024-12-30T00:00:00Z", tt.StartOfISOWeek().String())
+	})
+	t.Run("next-anniversary", func(t *testing.T) {
+		// Thursday 2021-04-30T06:37:41Z
+		SetClock(NewManualClock(time.Date(2021, time.April, 30, 6, 37, 41, 0, time.UTC)))
+		defer SetClock(nil)
+		later, _ := Date32(1990, time.October, 12, 18, 0, 0)
+		assert.Equal(t, "2021-10-12T00:00:00Z", later.NextAnniversary().String())
+		passed, _ := Date32(1990, time.January, 5, 0, 0, 0)
+		assert.Equal(t, "2022-01-05T00:00:00Z", passed.NextAnniversary().String())
+		today, _ := Date32(2000, time.April, 30, 23, 0, 0)
+		assert.Equal(t, "2021-04-30T00:00:00Z", today.NextAnniversary().String())
+		leap, _ := Date32(2020, time.February, 29, 12, 0, 0)
+		assert.Equal(t, "2022-03-01T00:00:00Z", leap.NextAnniversary().String())
+		SetClock(NewManualClock(time.Date(2023, time.May, 1, 0, 0, 0, 0, time.UTC)))
+		assert.Equal(t, "2024-02-29T00:00:00Z", leap.NextAnniversary().String())
+	})
+	t.Run("midnight-days-ago", func(t *testing.T) {
+		SetClock(NewManualClock(time.Unix(1588228661, 0)))
+		defer SetClock(nil)
+		assert.Equal(t, "2020-04-30T00:00:00Z", MidnightDaysAgo(0).String())
+		assert.Equal(t, "2020-04-23T00:00:00Z", MidnightDaysAgo(7).String())
+		assert.Equal(t, Time32(0), MidnightDaysAgo(math.MaxInt32))
+		assert.Panics(t, func() { MidnightDaysAgo(-1) })
+	})
+	t.Run("add-days-large", func(t *testing.T) {
+		// 30000*86400 overflows a 32-bit int
+		days := 30000
+		assert.Equal(t, Time32(2592000000), Time32(0).AddDate(days))
+		assert.Equal(t, Time32(2592000000), Time32(0).AddCalendarDays(days))
+		v, err := Time32(0).AddDaysChecked(days)
+		assert.NoError(t, err)
+		assert.Equal(t, Time32(2592000000), v)
+		v, err = Time32(2592000000).AddDaysChecked(-days)
+		assert.NoError(t, err)
+		assert.Equal(t, Time32(0), v)
+
+		_, err = Time32(0).AddDaysChecked(2 * days)
+		assert.Equal(t, ErrOutOfRange, err)
+		_, err = Time32(0).AddDaysChecked(-1)
+		assert.Equal(t, ErrOutOfRange, err)
+		assert.Equal(t, Time32(uint32(int64(2*days)*86400)), Time32(0).AddDate(2*days))
+	})
+	t.Run("same-day-month-year", func(t *testing.T) {
+		morning, _ := Date32(2020, time.April, 30, 0, 0, 0)
+		night, _ := Date32(2020, time.April, 30, 23, 59, 59)
+		nextDay, _ := Date32(2020, time.May, 1, 0, 0, 0)
+		nextMonth, _ := Date32(2020, time.May, 30, 12, 0, 0)
+		nextYear, _ := Date32(2021, time.April, 30, 12, 0, 0)
+		assert.True(t, SameDay(morning, night))
+		assert.False(t, SameDay(night, nextDay))
+		assert.False(t, SameDay(morning, nextYear))
+		assert.True(t, SameMonth(morning, night))
+		assert.False(t, SameMonth(night, nextDay))
+		assert.True(t, SameMonth(nextDay, nextMonth))
+		assert.False(t, SameMonth(morning, nextYear))
+		assert.True(t, SameYear(morning, nextMonth))
+		assert.False(t, SameYear(morning, nextYear))
+	})
+	t.Run("business-seconds", func(t *testing.T) {
+		// Thursday 2020-04-30
+		start, _ := Date32(2020, time.April, 30, 0, 0, 0)
+		end, _ := Date32(2020, time.May, 1, 0, 0, 0)
+		assert.Equal(t, int64(8*3600), BusinessSeconds(start, end, 9, 17))
+		// partial first and last day: Thursday 16:00 to Friday 10:30
+		start, _ = Date32(2020, time.April, 30, 16, 0, 0)
+		end, _ = Date32(2020, time.May, 1, 10, 30, 0)
+		assert.Equal(t, int64(3600+5400), BusinessSeconds(start, end, 9, 17))
+		// Friday 12:00 to Monday 12:00 spans a weekend
+		start, _ = Date32(2020, time.May, 1, 12, 0, 0)
+		end, _ = Date32(2020, time.May, 4, 12, 0, 0)
+		assert.Equal(t, int64(5*3600+3*3600), BusinessSeconds(start, end, 9, 17))
+		// outside business hours only: Thursday 18:00 to Friday 08:00
+		start, _ = Date32(2020, time.April, 30, 18, 0, 0)
+		end, _ = Date32(2020, time.May, 1, 8, 0, 0)
+		assert.Equal(t, int64(0), BusinessSeconds(start, end, 9, 17))
+		// whole weekend
+		start, _ = Date32(2020, time.May, 2, 0, 0, 0)
+		end, _ = Date32(2020, time.May, 4, 0, 0, 0)
+		assert.Equal(t, int64(0), BusinessSeconds(start, end, 0, 24))
+		assert.Equal(t, int64(0), BusinessSeconds(end, start, 9, 17))
+		assert.Panics(t, func() { BusinessSeconds(start, end, 17, 9) })
+		assert.Panics(t, func() { BusinessSeconds(start, end, 9, 25) })
+	})
+	t.Run("years-until", func(t *testing.T) {
+		birth, _ := Date32(1990, time.October, 12, 18, 0, 0)
+		birthday, _ := Date32(2020, time.October, 12, 0, 0, 0)
+		before, _ := Date32(2020, time.October, 11, 23, 59, 59)
+		after, _ := Date32(2020, time.October, 13, 0, 0, 0)
+		assert.Equal(t, 30, birth.YearsUntil(birthday))
+		assert.Equal(t, 29, birth.YearsUntil(before))
+		assert.Equal(t, 30, birth.YearsUntil(after))
+		assert.Equal(t, 0, birth.YearsUntil(birth))
+		assert.Equal(t, -30, birthday.YearsUntil(birth))
+
+		leap, _ := Date32(2000, time.February, 29, 0, 0, 0)
+		feb28, _ := Date32(2021, time.February, 28, 0, 0, 0)
+		mar1, _ := Date32(2021, time.March, 1, 0, 0, 0)
+		feb29, _ := Date32(2024, time.February, 29, 0, 0, 0)
+		assert.Equal(t, 20, leap.YearsUntil(feb28))
+		assert.Equal(t, 21, leap.YearsUntil(mar1))
+		assert.Equal(t, 24, leap.YearsUntil(feb29))
+	})
+	t.Run("deadline-from-context", func(t *testing.T) {
+		_, ok := DeadlineFromContext(context.Background())
+		assert.False(t, ok)
+
+		ctx, cancel := context.WithDeadline(context.Background(), time.Unix(1588228661, 999999999))
+		defer cancel()
+		d, ok := DeadlineFromContext(ctx)
+		assert.True(t, ok)
+		assert.Equal(t, Time32(1588228661), d)
+
+		ctx, cancel = context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+		d, ok = DeadlineFromContext(ctx)
+		assert.True(t, ok)
+		assert.InDelta(t, time.Now().Add(time.Minute).Unix(), int64(d), 1)
+
+		ctx, cancel = context.WithDeadline(context.Background(), time.Unix(1<<40, 0))
+		defer cancel()
+		d, ok = DeadlineFromContext(ctx)
+		assert.True(t, ok)
+		assert.Equal(t, Time32(math.MaxUint32), d)
+	})
+	t.Run("time32-range", func(t *testing.T) {
+		min, max := Time32Range()
+		assert.Equal(t, "1970-01-01T00:00:00Z", min.Format(time.RFC3339))
+		assert.Equal(t, "2106-02-07T06:28:15Z", max.Format(time.RFC3339))
+		assert.Equal(t, 2106, max.Year())
+		assert.Equal(t, Time32(0).ToTime(), min)
+		assert.Equal(t, Time32(math.MaxUint32).ToTime(), max)
+	})
+	t.Run("age-bucket", func(t *testing.T) {
+		now := Time32(1588228661)
+		SetClock(NewManualClock(time.Unix(int64(now), 0)))
+		defer SetClock(nil)
+		assert.Equal(t, "<1m", AgeBucket(now-30))
+		assert.Equal(t, "<1m", AgeBucket(now+30))
+		assert.Equal(t, "<5m", AgeBucket(now-60))
+		assert.Equal(t, "<1h", AgeBucket(now-10*60))
+		assert.Equal(t, "<1d", AgeBucket(now-3600))
+		assert.Equal(t, ">=1d", AgeBucket(now-2*86400))
+	})
+	t.Run("week-of-month", func(t *testing.T) {
+		// May 2020 starts on a Friday and has 31 days
+		first, _ := Date32(2020, time.May, 1, 12, 0, 0)
+		sunday, _ := Date32(2020, time.May, 3, 23, 59, 59)
+		monday, _ := Date32(2020, time.May, 4, 0, 0, 0)
+		mid, _ := Date32(2020, time.May, 15, 6, 0, 0)
+		last, _ := Date32(2020, time.May, 31, 12, 0, 0)
+		assert.Equal(t, 1, first.WeekOfMonth())
+		assert.Equal(t, 1, sunday.WeekOfMonth())
+		assert.Equal(t, 2, monday.WeekOfMonth())
+		assert.Equal(t, 3, mid.WeekOfMonth())
+		assert.Equal(t, 5, last.WeekOfMonth())
+
+		assert.Equal(t, 1, first.WeekOfMonthOn(time.Sunday))
+		assert.Equal(t, 2, sunday.WeekOfMonthOn(time.Sunday))
+		assert.Equal(t, 3, mid.WeekOfMonthOn(time.Sunday))
+		assert.Equal(t, 6, last.WeekOfMonthOn(time.Sunday))
+		assert.Equal(t, 5, last.WeekOfMonthOn(time.Friday))
+	})
+	t.Run("seconds-in-month", func(t *testing.T) {
+		leapFeb, _ := Date32(2020, time.February, 10, 0, 0, 0)
+		feb, _ := Date32(2021, time.February, 10, 0, 0, 0)
+		jan, _ := Date32(2021, time.January, 31, 23, 59, 59)
+		assert.Equal(t, 29, leapFeb.DaysInMonth())
+		assert.Equal(t, int64(29*86400), leapFeb.SecondsInMonth())
+		assert.Equal(t, int64(28*86400), feb.SecondsInMonth())
+		assert.Equal(t, int64(31*86400), jan.SecondsInMonth())
+	})
+}
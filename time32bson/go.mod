@@ -0,0 +1,19 @@
+module github.com/zerjioang/time32/time32bson
+
+go 1.17
+
+require (
+	github.com/stretchr/testify v1.7.0
+	github.com/zerjioang/time32 v0.0.0-20261016125010-33a94da49073
+	go.mongodb.org/mongo-driver v1.11.9
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
+
+// build against the parent module in this repository; consumers of this
+// module resolve the version required above
+replace github.com/zerjioang/time32 => ../
@@ -0,0 +1,34 @@
+//
+// Created by zerjioang
+// https://github/zerjioang
+// Copyright (c) 2020. All rights reserved.
+//
+// SPDX-License-Identifier: GPL-3.0
+//
+
+// Package time32bson converts time32.Time32 values to and from the Mongo
+// driver's BSON DateTime. It lives in its own module so that depending on
+// time32 does not pull the Mongo driver into a module graph.
+package time32bson
+
+import (
+	"github.com/zerjioang/time32"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"math"
+)
+
+// FromTime32 returns t as a BSON DateTime, the milliseconds since the Unix
+// epoch used by the Mongo driver.
+func FromTime32(t time32.Time32) primitive.DateTime {
+	return primitive.DateTime(int64(t) * 1000)
+}
+
+// ToTime32 returns the Time32 for the BSON DateTime d, truncating any
+// sub-second part. It returns time32.ErrOutOfRange if d is before the Unix
+// epoch or after the largest Time32.
+func ToTime32(d primitive.DateTime) (time32.Time32, error) {
+	if d < 0 || int64(d)/1000 > math.MaxUint32 {
+		return 0, time32.ErrOutOfRange
+	}
+	return time32.Time32(int64(d) / 1000), nil
+}
@@ -0,0 +1,40 @@
+//
+// Created by zerjioang
+// https://github/zerjioang
+// Copyright (c) 2020. All rights reserved.
+//
+// SPDX-License-Identifier: GPL-3.0
+//
+
+package time32bson
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/zerjioang/time32"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"math"
+	"testing"
+)
+
+func TestBSON(t *testing.T) {
+	t.Run("round-trip", func(t *testing.T) {
+		for _, tt := range []time32.Time32{0, 1588228661, math.MaxUint32} {
+			d := FromTime32(tt)
+			assert.Equal(t, int64(tt)*1000, int64(d))
+			back, err := ToTime32(d)
+			assert.NoError(t, err)
+			assert.Equal(t, tt, back)
+		}
+	})
+	t.Run("truncates-millis", func(t *testing.T) {
+		tt, err := ToTime32(primitive.DateTime(1588228661999))
+		assert.NoError(t, err)
+		assert.Equal(t, time32.Time32(1588228661), tt)
+	})
+	t.Run("out-of-range", func(t *testing.T) {
+		_, err := ToTime32(primitive.DateTime(-1))
+		assert.Equal(t, time32.ErrOutOfRange, err)
+		_, err = ToTime32(primitive.DateTime((math.MaxUint32 + 1) * 1000))
+		assert.Equal(t, time32.ErrOutOfRange, err)
+	})
+}
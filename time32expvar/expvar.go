@@ -0,0 +1,31 @@
+//
+// Created by zerjioang
+// https://github/zerjioang
+// Copyright (c) 2020. All rights reserved.
+//
+// SPDX-License-Identifier: GPL-3.0
+//
+
+// Package time32expvar publishes time32 values through the expvar package.
+// It is kept apart from time32 because importing expvar links net/http and
+// registers /debug/vars on http.DefaultServeMux.
+package time32expvar
+
+import (
+	"expvar"
+	"github.com/zerjioang/time32"
+)
+
+// PublishEpoch publishes an expvar.Func named name that reports the
+// current time32.Epoch as a JSON number, so /debug/vars shows time
+// progression. Like expvar.Publish, it panics if name is already registered.
+func PublishEpoch(name string) {
+	expvar.Publish(name, epochVar())
+}
+
+// epochVar returns the expvar.Func published by PublishEpoch.
+func epochVar() expvar.Func {
+	return func() interface{} {
+		return uint32(time32.Epoch())
+	}
+}
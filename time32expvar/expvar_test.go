@@ -0,0 +1,40 @@
+//
+// Created by zerjioang
+// https://github/zerjioang
+// Copyright (c) 2020. All rights reserved.
+//
+// SPDX-License-Identifier: GPL-3.0
+//
+
+package time32expvar
+
+import (
+	"expvar"
+	"github.com/stretchr/testify/assert"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestExpvar(t *testing.T) {
+	t.Run("epoch-var", func(t *testing.T) {
+		epoch, err := strconv.ParseInt(epochVar().String(), 10, 64)
+		assert.NoError(t, err)
+		assert.InDelta(t, time.Now().Unix(), epoch, 1)
+	})
+	t.Run("publish-epoch", func(t *testing.T) {
+		// the expvar registry is process wide, so use a new name on every
+		// run to support go test -count
+		name := "time32-test-epoch-" + strconv.Itoa(int(atomic.AddInt32(&expvarTestRuns, 1)))
+		PublishEpoch(name)
+		v := expvar.Get(name)
+		assert.NotNil(t, v)
+		epoch, err := strconv.ParseInt(v.String(), 10, 64)
+		assert.NoError(t, err)
+		assert.InDelta(t, time.Now().Unix(), epoch, 1)
+	})
+}
+
+// expvarTestRuns counts the runs of the publish-epoch test
+var expvarTestRuns int32
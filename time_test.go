@@ -11,6 +11,7 @@ package time32
 import (
 	"encoding/binary"
 	"fmt"
+	"github.com/stretchr/testify/assert"
 	"reflect"
 	"testing"
 	"time"
@@ -33,6 +34,13 @@ func TestNow(t *testing.T) {
 		t.Log(r)
 		t.Log(binary.Size(tt))
 	})
+	t.Run("since-now", func(t *testing.T) {
+		assert.NotPanics(t, func() {
+			d := Since(Now())
+			assert.True(t, d >= 0 && d < Second)
+		})
+		assert.True(t, startNano != 0)
+	})
 	t.Run("custom-epoch", func(t *testing.T) {
 		tt := Epoch()
 		t.Log(binary.Size(tt))
@@ -53,6 +61,201 @@ func TestNow(t *testing.T) {
 	})
 }
 
+func TestWallJumpSince(t *testing.T) {
+	t.Run("no-jump", func(t *testing.T) {
+		u := Now()
+		time.Sleep(10 * time.Millisecond)
+		tt := Now()
+		jump := tt.WallJumpSince(u)
+		if jump < 0 {
+			jump = -jump
+		}
+		assert.True(t, jump < Millisecond)
+	})
+	t.Run("forward-jump", func(t *testing.T) {
+		u := Now()
+		tt := u
+		tt.addSec(60)
+		assert.Equal(t, Minute, tt.WallJumpSince(u))
+	})
+	t.Run("without-monotonic", func(t *testing.T) {
+		u := Now()
+		tt := u.Add(Hour)
+		tt.stripMono()
+		assert.Equal(t, Duration(0), tt.WallJumpSince(u))
+	})
+}
+
+func TestEpoch(t *testing.T) {
+	t.Run("matches-standard-go", func(t *testing.T) {
+		for i := 0; i < 1000; i++ {
+			before := uint32(time.Now().Unix())
+			epoch := uint32(Epoch())
+			after := uint32(time.Now().Unix())
+			assert.True(t, before <= epoch && epoch <= after)
+		}
+	})
+	t.Run("matches-now", func(t *testing.T) {
+		for i := 0; i < 1000; i++ {
+			before := Now().Unix()
+			epoch := int64(Epoch())
+			after := Now().Unix()
+			assert.True(t, before <= epoch && epoch <= after)
+		}
+	})
+}
+
+func TestUsingLinkname(t *testing.T) {
+	t.Run("epoch-correct", func(t *testing.T) {
+		t.Logf("using linkname: %v", UsingLinkname())
+		before := time.Now().Unix()
+		epoch := int64(Epoch())
+		after := time.Now().Unix()
+		assert.True(t, before <= epoch && epoch <= after)
+		assert.InDelta(t, time.Now().UnixNano(), Now().UnixNano(), float64(time.Second))
+		assert.True(t, Uptime() > 0)
+	})
+}
+
+func TestEpochCoarse(t *testing.T) {
+	t.Run("accurate", func(t *testing.T) {
+		for i := 0; i < 100; i++ {
+			assert.InDelta(t, time.Now().Unix(), int64(EpochCoarse()), 1)
+			time.Sleep(time.Millisecond)
+		}
+	})
+	t.Run("manual-clock", func(t *testing.T) {
+		SetClock(NewManualClock(time.Unix(1588228661, 0)))
+		defer SetClock(nil)
+		assert.Equal(t, Time32(1588228661), EpochCoarse())
+	})
+}
+
+func TestEpochMillisOfDay(t *testing.T) {
+	t.Run("in-range", func(t *testing.T) {
+		for i := 0; i < 1000; i++ {
+			assert.True(t, EpochMillisOfDay() < 86400000)
+		}
+	})
+	t.Run("reconstructs", func(t *testing.T) {
+		before := time.Now().UnixNano() / 1e6
+		day, millis := EpochDayMillis()
+		after := time.Now().UnixNano() / 1e6
+		full := int64(day)*86400000 + int64(millis)
+		assert.True(t, before <= full && full <= after)
+	})
+	t.Run("manual-clock", func(t *testing.T) {
+		SetClock(NewManualClock(time.Unix(1588228661, 123456789)))
+		defer SetClock(nil)
+		day, millis := EpochDayMillis()
+		assert.Equal(t, uint32(18382), day)
+		assert.Equal(t, Time32(23861123), millis)
+		assert.Equal(t, millis, EpochMillisOfDay())
+		assert.Equal(t, int64(1588228661123), int64(day)*86400000+int64(millis))
+	})
+}
+
+func TestNowBoth(t *testing.T) {
+	t.Run("agree", func(t *testing.T) {
+		for i := 0; i < 1000; i++ {
+			tt, epoch := NowBoth()
+			assert.Equal(t, tt.Unix(), int64(epoch))
+		}
+	})
+	t.Run("manual-clock", func(t *testing.T) {
+		SetClock(NewManualClock(time.Unix(1588228661, 5)))
+		defer SetClock(nil)
+		tt, epoch := NowBoth()
+		assert.Equal(t, Time32(1588228661), epoch)
+		assert.Equal(t, int64(1588228661), tt.Unix())
+	})
+}
+
+func TestUptime(t *testing.T) {
+	t.Run("increases", func(t *testing.T) {
+		first := Uptime()
+		assert.True(t, first > 0)
+		time.Sleep(10 * time.Millisecond)
+		second := Uptime()
+		assert.True(t, second-first >= 10*Millisecond)
+	})
+}
+
+func TestAddOverflow(t *testing.T) {
+	t.Run("saturates-forward", func(t *testing.T) {
+		tt := Time{0, 1<<63 - 10}
+		a := tt.Add(maxDuration)
+		b := tt.Add(maxDuration)
+		assert.Equal(t, a, b)
+		assert.Equal(t, int64(1<<63-1), a.sec())
+		a2 := a.Add(maxDuration)
+		assert.Equal(t, int64(1<<63-1), a2.sec())
+		assert.True(t, a.After(tt))
+	})
+	t.Run("saturates-backward", func(t *testing.T) {
+		tt := Time{0, -(1<<63 - 10)}
+		a := tt.Add(minDuration)
+		assert.Equal(t, a, tt.Add(minDuration))
+		assert.Equal(t, int64(-(1<<63 - 1)), a.sec())
+		assert.True(t, a.Before(tt))
+	})
+	t.Run("monotonic-degrades", func(t *testing.T) {
+		tt := Now()
+		a := tt.Add(maxDuration)
+		assert.Equal(t, int64(0), a.mono())
+		assert.Equal(t, a, tt.Add(maxDuration))
+		assert.True(t, a.After(tt))
+	})
+}
+
+func TestNormalize(t *testing.T) {
+	t.Run("canonical", func(t *testing.T) {
+		tt := Unix(1588228661, 5)
+		assert.Equal(t, tt, tt.Normalize())
+	})
+	t.Run("wall-only", func(t *testing.T) {
+		abnormal := Time{wall: 1e9 + 5, ext: 1588228661 + unixToInternal}
+		n := abnormal.Normalize()
+		assert.Equal(t, int64(1588228662), n.Unix())
+		assert.Equal(t, 5, n.Nanosecond())
+	})
+	t.Run("constructors", func(t *testing.T) {
+		assert.Equal(t, Unix(1588228662, 5), unixTime(1588228661, 1e9+5))
+		assert.Equal(t, Unix(1588228660, 999999999), unixTime(1588228661, -1))
+		tt := nowTime(1588228661, 1e9+5, startNano+42)
+		assert.Equal(t, int64(1588228662), tt.Unix())
+		assert.Equal(t, 5, tt.Nanosecond())
+		assert.Equal(t, int64(42), tt.mono())
+	})
+	t.Run("monotonic", func(t *testing.T) {
+		tt := Now()
+		abnormal := tt
+		abnormal.wall = abnormal.wall&^nsecMask | (1e9 + 5)
+		n := abnormal.Normalize()
+		assert.Equal(t, tt.Unix()+1, n.Unix())
+		assert.Equal(t, 5, n.Nanosecond())
+		assert.Equal(t, tt.mono(), n.mono())
+	})
+}
+
+func TestDuration(t *testing.T) {
+	t.Run("abs", func(t *testing.T) {
+		assert.Equal(t, Second, Second.Abs())
+		assert.Equal(t, Second, (-Second).Abs())
+		assert.Equal(t, Duration(0), Duration(0).Abs())
+		assert.Equal(t, maxDuration, minDuration.Abs())
+		assert.Equal(t, maxDuration, (minDuration + 1).Abs())
+	})
+	t.Run("clamp", func(t *testing.T) {
+		assert.Equal(t, Second, Millisecond.Clamp(Second, Minute))
+		assert.Equal(t, Minute, Hour.Clamp(Second, Minute))
+		assert.Equal(t, 30*Second, (30*Second).Clamp(Second, Minute))
+		assert.Equal(t, Second, Hour.Clamp(Second, Second))
+		assert.Equal(t, Second, Millisecond.Clamp(Second, Second))
+		assert.Panics(t, func() { Second.Clamp(Minute, Second) })
+	})
+}
+
 func BenchmarkNow(b *testing.B) {
 	// BenchmarkNow/epoch-custom-12         	     232	   5111623 ns/op	   0.00 MB/s	       0 B/op	       0 allocs/op
 	b.Run("epoch-custom", func(b *testing.B) {
@@ -78,6 +281,17 @@ func BenchmarkNow(b *testing.B) {
 			}
 		}
 	})
+	b.Run("epoch-coarse", func(b *testing.B) {
+		b.ReportAllocs()
+		b.SetBytes(1)
+		b.ResetTimer()
+		var stamps [100000]Time32
+		for i := 0; i < b.N; i++ {
+			for i := 0; i < 100000; i++ {
+				stamps[i] = EpochCoarse()
+			}
+		}
+	})
 	b.Run("custom", func(b *testing.B) {
 		b.ReportAllocs()
 		b.SetBytes(1)
@@ -179,3 +393,31 @@ func BenchmarkNow(b *testing.B) {
 		}
 	})
 }
+
+func BenchmarkNowBoth(b *testing.B) {
+	b.Run("now-both", func(b *testing.B) {
+		var epoch Time32
+		b.ReportAllocs()
+		b.SetBytes(1)
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			_, epoch = NowBoth()
+		}
+		if epoch == 0 {
+			b.Log("time is zero")
+		}
+	})
+	b.Run("now-and-epoch", func(b *testing.B) {
+		var epoch Time32
+		b.ReportAllocs()
+		b.SetBytes(1)
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			_ = Now()
+			epoch = Epoch()
+		}
+		if epoch == 0 {
+			b.Log("time is zero")
+		}
+	})
+}